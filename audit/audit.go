@@ -0,0 +1,47 @@
+// Package audit carries the origin of the changes, who made them and as part
+// of which request, from the transports down to the repositories, which
+// record each change in the history of the passenger.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gitlab.com/hyperd/titanic"
+)
+
+// Origin identifies who made a change, and as part of which request.
+type Origin struct {
+	Actor     string
+	RequestID string
+}
+
+type originKey struct{}
+
+// NewContext returns a copy of ctx carrying the origin.
+func NewContext(ctx context.Context, o Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, o)
+}
+
+// FromContext returns the origin carried by ctx, or the zero Origin.
+func FromContext(ctx context.Context) Origin {
+	o, _ := ctx.Value(originKey{}).(Origin)
+	return o
+}
+
+// NewChange returns the change made by the origin carried by ctx to the
+// passenger, given its state before and after the change.
+func NewChange(ctx context.Context, action string, id uuid.UUID, before, after *titanic.People) titanic.Change {
+	o := FromContext(ctx)
+	return titanic.Change{
+		ID:        uuid.New(),
+		PeopleID:  id,
+		Action:    action,
+		Actor:     o.Actor,
+		RequestID: o.RequestID,
+		At:        time.Now(),
+		Before:    before,
+		After:     after,
+	}
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scopes granted by the API keys, mapped to the roles of the same access.
+const (
+	ScopeRead  = "people:read"
+	ScopeWrite = "people:write"
+	ScopeAdmin = "people:admin"
+)
+
+// scopeRoles are the roles granted by the scopes; people:read grants none, as
+// reading doesn't require any.
+var scopeRoles = map[string]string{
+	ScopeRead:  "",
+	ScopeWrite: RoleEditor,
+	ScopeAdmin: RoleAdmin,
+}
+
+var (
+	// ErrInvalidKey is returned when the API key presented by the caller is
+	// malformed, revoked, or was never minted.
+	ErrInvalidKey = errors.New("invalid API key")
+
+	// ErrKeyNotFound is returned by the key stores for an unknown key id.
+	ErrKeyNotFound = errors.New("API key not found")
+)
+
+// keyPrefix starts all of the API keys, making them easy to spot, e.g. by
+// secret scanners.
+const keyPrefix = "tk_"
+
+// APIKey is the record of an API key kept by the key stores: only a salted
+// hash of its secret is kept, the key itself being shown once, when minted.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	Salt      string    `json:"salt"`
+	Hash      string    `json:"hash"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Claims returns the claims of the callers presenting the key.
+func (k APIKey) Claims() Claims {
+	c := Claims{Subject: "apikey:" + k.ID}
+	for _, s := range k.Scopes {
+		if role := scopeRoles[s]; role != "" {
+			c.Roles = append(c.Roles, role)
+		}
+	}
+	return c
+}
+
+// KeyStore stores the API keys by id. Implementations must be safe for
+// concurrent use.
+type KeyStore interface {
+	PutKey(k APIKey) error
+	GetKey(id string) (APIKey, error)
+	DeleteKey(id string) error
+	ListKeys() ([]APIKey, error)
+}
+
+// MintKey mints a new API key granting the scopes, and stores its record. The
+// key is returned once and for all: it can't be recovered from the store.
+func MintKey(store KeyStore, name string, scopes []string) (string, APIKey, error) {
+	if len(scopes) == 0 {
+		return "", APIKey{}, errors.New("no scope")
+	}
+	for _, s := range scopes {
+		if _, ok := scopeRoles[s]; !ok {
+			return "", APIKey{}, fmt.Errorf("unknown scope %q", s)
+		}
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	salt, err := randomHex(16)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+
+	k := APIKey{
+		ID:        id,
+		Name:      name,
+		Salt:      salt,
+		Hash:      hashSecret(salt, secret),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := store.PutKey(k); err != nil {
+		return "", APIKey{}, err
+	}
+	return keyPrefix + id + "." + secret, k, nil
+}
+
+// VerifyKey returns the record of the API key, provided it was minted, and
+// hasn't been revoked since.
+func VerifyKey(store KeyStore, key string) (APIKey, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return APIKey{}, ErrInvalidKey
+	}
+	parts := strings.SplitN(strings.TrimPrefix(key, keyPrefix), ".", 2)
+	if len(parts) != 2 {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	k, err := store.GetKey(parts[0])
+	if err == ErrKeyNotFound {
+		return APIKey{}, ErrInvalidKey
+	} else if err != nil {
+		return APIKey{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(k.Salt, parts[1])), []byte(k.Hash)) != 1 {
+		return APIKey{}, ErrInvalidKey
+	}
+	return k, nil
+}
+
+func hashSecret(salt, secret string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,119 @@
+// Package auth authenticates the callers of the service with JWT bearer
+// tokens, signed with HS256 or RS256, or with API keys, and carries their
+// claims from the transports down to the authorization middleware.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Roles granted by the tokens. An admin is an editor as well.
+const (
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+var (
+	// ErrMissingToken is returned when the caller didn't present a token.
+	ErrMissingToken = errors.New("missing bearer token")
+
+	// ErrInvalidToken is returned when the token presented by the caller
+	// is malformed, expired, or not signed by any of the trusted keys.
+	ErrInvalidToken = errors.New("invalid bearer token")
+)
+
+// Claims are the claims of a token the service is interested in.
+type Claims struct {
+	Subject   string   `json:"sub,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+}
+
+// UnnamedActor is the actor of the changes made with credentials naming no
+// subject.
+const UnnamedActor = "unnamed"
+
+// Actor returns the actor the changes made with the claims are attributed
+// to: their subject, or else UnnamedActor, never the actor claimed by the
+// caller.
+func (c Claims) Actor() string {
+	if c.Subject == "" {
+		return UnnamedActor
+	}
+	return c.Subject
+}
+
+// HasRole reports whether the claims grant the role.
+func (c Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type identity struct {
+	claims Claims
+	err    error
+}
+
+type identityKey struct{}
+
+// Authenticator authenticates the callers by their JWT bearer token, or else
+// by their API key.
+type Authenticator struct {
+	// Tokens verifies the bearer tokens; they are all rejected if nil.
+	Tokens *Verifier
+	// Keys stores the API keys; they are all rejected if nil.
+	Keys KeyStore
+}
+
+// Authenticate returns a copy of ctx carrying the claims of the bearer token,
+// or else of the API key, or the reason why they aren't valid. Without
+// either, the caller is left anonymous.
+func (a *Authenticator) Authenticate(ctx context.Context, token, key string) context.Context {
+	var id identity
+	switch {
+	case token != "":
+		id.err = ErrInvalidToken
+		if a.Tokens != nil {
+			id.claims, id.err = a.Tokens.Verify(token)
+		}
+	case key != "":
+		id.err = ErrInvalidKey
+		if a.Keys != nil {
+			var k APIKey
+			if k, id.err = VerifyKey(a.Keys, key); id.err == nil {
+				id.claims = k.Claims()
+			}
+		}
+	default:
+		return ctx
+	}
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the claims carried by ctx; it fails with
+// ErrMissingToken for anonymous callers, and with ErrInvalidToken or
+// ErrInvalidKey for those who presented invalid credentials.
+func FromContext(ctx context.Context) (Claims, error) {
+	id, ok := ctx.Value(identityKey{}).(identity)
+	if !ok {
+		return Claims{}, ErrMissingToken
+	}
+	return id.claims, id.err
+}
+
+// BearerToken returns the token of an Authorization header value of the
+// Bearer scheme, or "".
+func BearerToken(authorization string) string {
+	const prefix = "bearer "
+	if len(authorization) < len(prefix) || strings.ToLower(authorization[:len(prefix)]) != prefix {
+		return ""
+	}
+	return strings.TrimSpace(authorization[len(prefix):])
+}
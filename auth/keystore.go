@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewInmemKeyStore returns an empty KeyStore keeping the keys in memory.
+func NewInmemKeyStore() KeyStore {
+	return &inmemKeyStore{keys: map[string]APIKey{}}
+}
+
+type inmemKeyStore struct {
+	mtx  sync.RWMutex
+	keys map[string]APIKey
+}
+
+func (s *inmemKeyStore) PutKey(k APIKey) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.keys[k.ID] = k
+	return nil
+}
+
+func (s *inmemKeyStore) GetKey(id string) (APIKey, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return APIKey{}, ErrKeyNotFound
+	}
+	return k, nil
+}
+
+func (s *inmemKeyStore) DeleteKey(id string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.keys[id]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(s.keys, id)
+	return nil
+}
+
+func (s *inmemKeyStore) ListKeys() ([]APIKey, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return sortedKeys(s.keys), nil
+}
+
+// NewFileKeyStore returns a KeyStore keeping the keys in a JSON file, created
+// when first written. The file is read again whenever it changes, so that
+// the keys minted or revoked by another process, e.g. the titanic apikey
+// command, are taken into account without a restart.
+func NewFileKeyStore(path string) (KeyStore, error) {
+	s := &fileKeyStore{path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type fileKeyStore struct {
+	path string
+
+	mtx     sync.Mutex
+	keys    map[string]APIKey
+	modTime time.Time
+	size    int64
+}
+
+// load reads the file again, if it changed since last read.
+func (s *fileKeyStore) load() error {
+	fi, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.keys, s.modTime, s.size = map[string]APIKey{}, time.Time{}, 0
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if s.keys != nil && fi.ModTime().Equal(s.modTime) && fi.Size() == s.size {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var keys []APIKey
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return err
+		}
+	}
+	s.keys = make(map[string]APIKey, len(keys))
+	for _, k := range keys {
+		s.keys[k.ID] = k
+	}
+	s.modTime, s.size = fi.ModTime(), fi.Size()
+	return nil
+}
+
+// save writes the keys to a temporary file, renamed over the file, so that
+// readers never see it half written.
+func (s *fileKeyStore) save() error {
+	data, err := json.MarshalIndent(sortedKeys(s.keys), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	return s.load()
+}
+
+func (s *fileKeyStore) PutKey(k APIKey) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.keys[k.ID] = k
+	return s.save()
+}
+
+func (s *fileKeyStore) GetKey(id string) (APIKey, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.load(); err != nil {
+		return APIKey{}, err
+	}
+	k, ok := s.keys[id]
+	if !ok {
+		return APIKey{}, ErrKeyNotFound
+	}
+	return k, nil
+}
+
+func (s *fileKeyStore) DeleteKey(id string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.keys[id]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(s.keys, id)
+	return s.save()
+}
+
+func (s *fileKeyStore) ListKeys() ([]APIKey, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return sortedKeys(s.keys), nil
+}
+
+// sortedKeys returns the keys, oldest first.
+func sortedKeys(m map[string]APIKey) []APIKey {
+	keys := make([]APIKey, 0, len(m))
+	for _, k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
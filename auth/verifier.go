@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Verifier verifies the tokens against a set of trusted keys: HMAC secrets
+// for HS256, and RSA public keys for RS256, identified by the kid header of
+// the tokens.
+type Verifier struct {
+	keys map[string]interface{} // []byte or *rsa.PublicKey, by kid
+}
+
+// LoadVerifier returns a Verifier trusting the keys of the file, as parsed
+// by NewVerifier.
+func LoadVerifier(path string) (*Verifier, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifier(data)
+}
+
+// NewVerifier returns a Verifier trusting the given keys: either a JWKS
+// document, a PEM encoded RSA public key or certificate, or else an HMAC
+// secret.
+func NewVerifier(data []byte) (*Verifier, error) {
+	v := &Verifier{keys: map[string]interface{}{}}
+
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0:
+		return nil, errors.New("no key")
+	case data[0] == '{':
+		if err := v.addJWKS(data); err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(data, []byte("-----BEGIN")):
+		key, err := parseRSAPublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+		v.keys[""] = key
+	default:
+		v.keys[""] = data
+	}
+	return v, nil
+}
+
+// Verify returns the claims of the token, provided it is signed by one of
+// the trusted keys, and currently valid.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	var claims Claims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return claims, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+	if !v.verifySignature(header.Alg, v.key(header.Kid), parts[0]+"."+parts[1], signature) {
+		return claims, ErrInvalidToken
+	}
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	now := time.Now().Unix()
+	if claims.ExpiresAt != 0 && now >= claims.ExpiresAt {
+		return Claims{}, ErrInvalidToken
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// key returns the key identified by kid, falling back to the only key, if
+// there's just one.
+func (v *Verifier) key(kid string) interface{} {
+	if key, ok := v.keys[kid]; ok {
+		return key
+	}
+	if len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// verifySignature verifies the signature of the signed input. The algorithm
+// must match the type of the key, so that e.g. an RSA public key is never
+// used as an HMAC secret.
+func (v *Verifier) verifySignature(alg string, key interface{}, signed string, signature []byte) bool {
+	switch key := key.(type) {
+	case []byte:
+		if alg != "HS256" {
+			return false
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		return hmac.Equal(mac.Sum(nil), signature)
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			return false
+		}
+		digest := sha256.Sum256([]byte(signed))
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
+
+func (v *Verifier) addJWKS(data []byte) error {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return err
+	}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return err
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				return err
+			}
+			v.keys[k.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return err
+			}
+			v.keys[k.Kid] = secret
+		}
+	}
+	if len(v.keys) == 0 {
+		return errors.New("no signing key in JWKS")
+	}
+	return nil
+}
+
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM")
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = cert.PublicKey
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		var err error
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	}
+	if rsaKey, ok := key.(*rsa.PublicKey); ok {
+		return rsaKey, nil
+	}
+	return nil, errors.New("not an RSA public key")
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token.
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"gitlab.com/hyperd/titanic/auth"
+)
+
+// runAPIKey implements the apikey subcommand, managing the API keys of the
+// file read by the API with -auth.apikeys:
+//
+//	titanic apikey mint -file apikeys.json -name nightly-import -scopes people:read,people:write
+//	titanic apikey revoke -file apikeys.json -id 3f2a9c1b7d4e5f60
+//	titanic apikey list -file apikeys.json
+//
+// The minted key is printed on the standard output, and can't be recovered
+// afterwards.
+func runAPIKey(args []string) int {
+	logger := newLogger()
+
+	if len(args) == 0 {
+		level.Error(logger).Log("exit", "usage: titanic apikey mint|revoke|list [flags]")
+		return 2
+	}
+	command, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("apikey "+command, flag.ExitOnError)
+	cfg := registerAPIKeyFlags(fs)
+	fs.Parse(args)
+
+	store, err := auth.NewFileKeyStore(cfg.File)
+	if err != nil {
+		level.Error(logger).Log("exit", err)
+		return 1
+	}
+
+	switch command {
+	case "mint":
+		key, k, err := auth.MintKey(store, cfg.Name, strings.Split(cfg.Scopes, ","))
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			return 1
+		}
+		level.Info(logger).Log("msg", "API key minted", "id", k.ID, "name", k.Name, "scopes", strings.Join(k.Scopes, ","))
+		fmt.Println(key)
+
+	case "revoke":
+		if err := store.DeleteKey(cfg.ID); err != nil {
+			level.Error(logger).Log("exit", err, "id", cfg.ID)
+			return 1
+		}
+		level.Info(logger).Log("msg", "API key revoked", "id", cfg.ID)
+
+	case "list":
+		keys, err := store.ListKeys()
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSCOPES\tCREATED")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.ID, k.Name, strings.Join(k.Scopes, ","), k.CreatedAt.Format(time.RFC3339))
+		}
+		w.Flush()
+
+	default:
+		level.Error(logger).Log("exit", fmt.Sprintf("unknown apikey command %q", command))
+		return 2
+	}
+	return 0
+}
+
+// apiKeyConfig configures the apikey subcommand.
+type apiKeyConfig struct {
+	File   string
+	Name   string
+	Scopes string
+	ID     string
+}
+
+// registerAPIKeyFlags registers the flags of the apikey subcommand on fs.
+func registerAPIKeyFlags(fs *flag.FlagSet) *apiKeyConfig {
+	var c apiKeyConfig
+	fs.StringVar(&c.File, "file", "apikeys.json", "File of the API keys")
+	fs.StringVar(&c.Name, "name", "", "Name of the minted key, e.g. the job using it")
+	fs.StringVar(&c.Scopes, "scopes", auth.ScopeRead, "Comma separated scopes of the minted key: people:read, people:write, people:admin")
+	fs.StringVar(&c.ID, "id", "", "Id of the revoked key")
+	return &c
+}
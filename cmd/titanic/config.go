@@ -0,0 +1,260 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gitlab.com/hyperd/titanic/cockroachdb"
+	"gitlab.com/hyperd/titanic/config"
+	"gitlab.com/hyperd/titanic/inmemory"
+	"gitlab.com/hyperd/titanic/ratelimit"
+)
+
+// defaultDSN is the database of the docker-compose setup.
+const defaultDSN = "postgresql://d4gh0s7@roach1:26257/titanic?sslmode=disable"
+
+// serverConfig configures the API server.
+type serverConfig struct {
+	HTTPAddr  string
+	HTTPSAddr string
+	GRPCAddr  string
+	TLSCert   string
+	TLSKey    string
+
+	TracingExporter  string
+	AuthKeys         string
+	AuthAPIKeys      string
+	RateLimitRate    float64
+	RateLimitBurst   int
+	RateLimitCosts   string
+	RateLimitProxies string
+	IdempotencyTTL   time.Duration
+
+	Database *databaseConfig
+}
+
+// registerServerFlags registers the flags of the server configuration on fs.
+func registerServerFlags(fs *flag.FlagSet) *serverConfig {
+	c := serverConfig{Database: registerDatabaseFlags(fs)}
+	fs.StringVar(&c.HTTPAddr, "http.addr", ":3000", "HTTP listen address")
+	fs.StringVar(&c.HTTPSAddr, "https.addr", ":8443", "HTTPS listen address; HTTPS is disabled if empty")
+	fs.StringVar(&c.GRPCAddr, "grpc.addr", ":8082", "gRPC listen address")
+	fs.StringVar(&c.TLSCert, "https.tls.cert", "/etc/tls/certs/tls.crt", "Certificate of the HTTPS server")
+	fs.StringVar(&c.TLSKey, "https.tls.key", "/etc/tls/certs/tls.key", "Key of the certificate of the HTTPS server")
+	fs.StringVar(&c.TracingExporter, "tracing.exporter", "none", "Tracing exporter: none or stdout")
+	fs.StringVar(&c.AuthKeys, "auth.keys", "", "File of the keys verifying the JWT bearer tokens: a JWKS, a PEM RSA public key or an HMAC secret; authentication is disabled if empty")
+	fs.StringVar(&c.AuthAPIKeys, "auth.apikeys", "", "File of the API keys, as managed by titanic apikey; API keys are disabled if empty")
+	fs.Float64Var(&c.RateLimitRate, "ratelimit.rate", 0, "Tokens per second refilled to the bucket of each client; rate limiting is disabled if 0")
+	fs.IntVar(&c.RateLimitBurst, "ratelimit.burst", 100, "Tokens held by the bucket of each client")
+	fs.StringVar(&c.RateLimitCosts, "ratelimit.costs", "", "Comma separated method=cost pairs overriding the default costs of the methods, in tokens, e.g. GetPeople=2")
+	fs.StringVar(&c.RateLimitProxies, "ratelimit.trusted-proxies", "", "Comma separated addresses or CIDR ranges of the proxies trusted to report the address of the clients in X-Forwarded-For, e.g. the ingress; X-Forwarded-For is ignored if empty")
+	fs.DurationVar(&c.IdempotencyTTL, "idempotency.ttl", 24*time.Hour, "How long the responses to the requests carrying an Idempotency-Key are kept; idempotency keys are ignored if 0")
+	return &c
+}
+
+// commandFlags register the flags of the server and of its subcommands,
+// which share the configuration file.
+var commandFlags = map[string]func(*flag.FlagSet){
+	"titanic": func(fs *flag.FlagSet) { registerServerFlags(fs) },
+	"import":  func(fs *flag.FlagSet) { registerImportFlags(fs) },
+	"purge":   func(fs *flag.FlagSet) { registerPurgeFlags(fs) },
+	"apikey":  func(fs *flag.FlagSet) { registerAPIKeyFlags(fs) },
+}
+
+// otherFlags returns the flags of the commands other than the given one,
+// so that the settings of the shared configuration file it doesn't have
+// aren't taken for unknown ones.
+func otherFlags(command string) []*flag.FlagSet {
+	var others []*flag.FlagSet
+	for name, register := range commandFlags {
+		if name == command {
+			continue
+		}
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		register(fs)
+		others = append(others, fs)
+	}
+	return others
+}
+
+// validate checks the configuration, before starting the server.
+func (c serverConfig) validate() error {
+	if c.HTTPAddr == "" || c.GRPCAddr == "" {
+		return fmt.Errorf("-http.addr and -grpc.addr: missing listen address")
+	}
+	if c.HTTPSAddr != "" {
+		if c.TLSCert == "" || c.TLSKey == "" {
+			return fmt.Errorf("-https.tls.cert and -https.tls.key must be set, unless -https.addr is empty")
+		}
+		if err := checkFile("https.tls.cert", c.TLSCert); err != nil {
+			return err
+		}
+		if err := checkFile("https.tls.key", c.TLSKey); err != nil {
+			return err
+		}
+	}
+	switch c.TracingExporter {
+	case "none", "stdout":
+	default:
+		return fmt.Errorf("-tracing.exporter: unknown tracing exporter %q", c.TracingExporter)
+	}
+	if c.RateLimitRate < 0 || c.RateLimitBurst < 1 {
+		return fmt.Errorf("-ratelimit.rate must not be negative, and -ratelimit.burst must be positive")
+	}
+	if _, err := ratelimit.ParseProxies(c.RateLimitProxies); err != nil {
+		return fmt.Errorf("-ratelimit.trusted-proxies: %v", err)
+	}
+	if c.IdempotencyTTL < 0 {
+		return fmt.Errorf("-idempotency.ttl: must not be negative")
+	}
+	return c.Database.validate()
+}
+
+// databaseConfig configures the connection to the database, for the server
+// and the subcommands alike.
+type databaseConfig struct {
+	Type string
+	DSN  string
+	Path string
+
+	// The CA certificate verifying the server, and the client certificate
+	// and key authenticating the user, e.g. ca.crt, client.root.crt and
+	// client.root.key in the certs directory of a secure CockroachDB.
+	TLSCA   string
+	TLSCert string
+	TLSKey  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	Timeouts string
+
+	// The persistence of the in-memory storage, which is lost on exit if
+	// InmemoryDir is empty.
+	InmemoryDir              string
+	InmemorySync             string
+	InmemorySyncInterval     time.Duration
+	InmemorySnapshotInterval time.Duration
+}
+
+// registerDatabaseFlags registers the flags of the database configuration
+// on fs, along with the flag naming the configuration file.
+func registerDatabaseFlags(fs *flag.FlagSet) *databaseConfig {
+	var c databaseConfig
+	fs.String(config.FileFlag, "", "YAML or TOML configuration file, whose settings are overridden by the "+config.EnvPrefix+"* environment variables and by the flags")
+	fs.StringVar(&c.Type, "database.type", "cockroachdb", "Database type: cockroachdb, sqlite or inmemory")
+	fs.StringVar(&c.DSN, "database.dsn", defaultDSN, "Connection string of the CockroachDB database")
+	fs.StringVar(&c.Path, "database.path", "titanic.db", "File of the SQLite database, created if missing")
+	fs.StringVar(&c.TLSCA, "database.tls.ca", "", "CA certificate verifying the database server; the connection isn't encrypted if empty")
+	fs.StringVar(&c.TLSCert, "database.tls.cert", "", "Client certificate authenticating the database user")
+	fs.StringVar(&c.TLSKey, "database.tls.key", "", "Key of the client certificate")
+	fs.IntVar(&c.MaxOpenConns, "database.pool.max-open", 0, "Maximum number of open connections to the database; unlimited if 0")
+	fs.IntVar(&c.MaxIdleConns, "database.pool.max-idle", 2, "Maximum number of idle connections kept open")
+	fs.DurationVar(&c.ConnMaxLifetime, "database.pool.max-lifetime", 0, "Maximum time a connection is reused; forever if 0")
+	fs.StringVar(&c.InmemoryDir, "database.inmemory.dir", "", "Directory of the write-ahead log and of the snapshots of the in-memory storage, created if missing; the storage is lost on exit if empty")
+	fs.StringVar(&c.InmemorySync, "database.inmemory.sync", "interval", "When the write-ahead log is flushed to the disk: always, on every mutation; interval; or never, leaving it to the operating system")
+	fs.DurationVar(&c.InmemorySyncInterval, "database.inmemory.sync-interval", time.Second, "Interval of the flushes of the write-ahead log, with -database.inmemory.sync=interval")
+	fs.DurationVar(&c.InmemorySnapshotInterval, "database.inmemory.snapshot-interval", 5*time.Minute, "Interval of the snapshots compacting the write-ahead log; only on exit if 0")
+	fs.StringVar(&c.Timeouts, "database.timeouts", "", "Comma separated method=timeout pairs overriding the default timeouts of the statements of the repository methods, e.g. GetPeople=2s; none if 0")
+	return &c
+}
+
+// validate checks the configuration, before connecting to the database.
+func (c databaseConfig) validate() error {
+	switch c.Type {
+	case "inmemory":
+		if c.InmemoryDir == "" {
+			return nil
+		}
+		if _, err := inmemory.ParseSyncPolicy(c.InmemorySync); err != nil {
+			return fmt.Errorf("-database.inmemory.sync: %v", err)
+		}
+		if c.InmemorySync == string(inmemory.SyncInterval) && c.InmemorySyncInterval <= 0 {
+			return fmt.Errorf("-database.inmemory.sync-interval: must be positive")
+		}
+		if c.InmemorySnapshotInterval < 0 {
+			return fmt.Errorf("-database.inmemory.snapshot-interval: must not be negative")
+		}
+		return nil
+	case "sqlite":
+		if c.Path == "" {
+			return fmt.Errorf("-database.path: missing SQLite database file")
+		}
+		return nil
+	case "cockroachdb":
+	default:
+		return fmt.Errorf("-database.type: unknown database type %q", c.Type)
+	}
+	if c.DSN == "" {
+		return fmt.Errorf("-database.dsn: missing connection string")
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("-database.tls.cert and -database.tls.key must be set together")
+	}
+	if c.TLSCert != "" && c.TLSCA == "" {
+		return fmt.Errorf("-database.tls.ca must be set along with the client certificate")
+	}
+	for flag, file := range map[string]string{"database.tls.ca": c.TLSCA, "database.tls.cert": c.TLSCert, "database.tls.key": c.TLSKey} {
+		if err := checkFile(flag, file); err != nil {
+			return err
+		}
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 || c.ConnMaxLifetime < 0 {
+		return fmt.Errorf("-database.pool.*: must not be negative")
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		return fmt.Errorf("-database.pool.max-idle: must not exceed -database.pool.max-open")
+	}
+	if _, err := cockroachdb.ParseTimeouts(c.Timeouts); err != nil {
+		return fmt.Errorf("-database.timeouts: %v", err)
+	}
+	return nil
+}
+
+// dataSource returns the connection string of the database, verifying the
+// server and authenticating the user with the TLS certificates, if set.
+func (c databaseConfig) dataSource() (string, error) {
+	if c.TLSCA == "" {
+		return c.DSN, nil
+	}
+	params := [][2]string{{"sslmode", "verify-full"}, {"sslrootcert", c.TLSCA}}
+	if c.TLSCert != "" {
+		params = append(params, [2]string{"sslcert", c.TLSCert}, [2]string{"sslkey", c.TLSKey})
+	}
+
+	if strings.HasPrefix(c.DSN, "postgres://") || strings.HasPrefix(c.DSN, "postgresql://") {
+		u, err := url.Parse(c.DSN)
+		if err != nil {
+			return "", fmt.Errorf("-database.dsn: %v", err)
+		}
+		q := u.Query()
+		for _, p := range params {
+			q.Set(p[0], p[1])
+		}
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	// A key=value connection string, whose last settings win.
+	dsn := c.DSN
+	for _, p := range params {
+		dsn += fmt.Sprintf(" %s='%s'", p[0], strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(p[1]))
+	}
+	return dsn, nil
+}
+
+// checkFile checks that the file set by the flag, if any, can be read.
+func checkFile(flag, file string) error {
+	if file == "" {
+		return nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("-%s: %v", flag, err)
+	}
+	return f.Close()
+}
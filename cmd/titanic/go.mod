@@ -1,3 +1,25 @@
 module gitlab.com/hyperd/titanic/cmd/titanic
 
 go 1.13
+
+require (
+	github.com/go-kit/kit v0.9.0
+	github.com/jinzhu/gorm v1.9.11
+	github.com/prometheus/client_golang v1.12.2
+	gitlab.com/hyperd/titanic v0.0.0-00010101000000-000000000000
+	gitlab.com/hyperd/titanic/implementation v0.0.0-00010101000000-000000000000
+	gitlab.com/hyperd/titanic/inmemory v0.0.0-00010101000000-000000000000
+	gitlab.com/hyperd/titanic/transport/grpc v0.0.0-00010101000000-000000000000
+	gitlab.com/hyperd/titanic/transport/http v0.0.0-00010101000000-000000000000
+	golang.org/x/net v0.0.0-20210525063256-abc453219eb5
+	google.golang.org/grpc v1.40.0
+)
+
+replace (
+	gitlab.com/hyperd/titanic => ../..
+	gitlab.com/hyperd/titanic/implementation => ../../implementation
+	gitlab.com/hyperd/titanic/inmemory => ../../inmemory
+	gitlab.com/hyperd/titanic/transport => ../../transport
+	gitlab.com/hyperd/titanic/transport/grpc => ../../transport/grpc
+	gitlab.com/hyperd/titanic/transport/http => ../../transport/http
+)
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/go-kit/kit/log/level"
+	"gitlab.com/hyperd/titanic/config"
+	titanicsvc "gitlab.com/hyperd/titanic/implementation"
+	"gitlab.com/hyperd/titanic/importer"
+)
+
+// runImport implements the import subcommand, loading a CSV file laid out as
+// data/titanic.csv into the selected database:
+//
+//	titanic import -file data/titanic.csv -database.type cockroachdb
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	cfg := registerImportFlags(fs)
+	logger := newLogger()
+	if err := config.Parse(fs, args, otherFlags("import")...); err != nil {
+		level.Error(logger).Log("exit", err)
+		return 2
+	}
+	if err := cfg.Database.validate(); err != nil {
+		level.Error(logger).Log("exit", err)
+		return 2
+	}
+
+	var r io.Reader = os.Stdin
+	if cfg.File != "-" {
+		f, err := os.Open(cfg.File)
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+
+	repository, closeRepository, err := newRepository(*cfg.Database, logger)
+	if err != nil {
+		level.Error(logger).Log("exit", err)
+		return 1
+	}
+	defer closeRepository()
+
+	svc := titanicsvc.NewService(repository, logger)
+
+	report, err := importer.New(svc, cfg.BatchSize, logger).Import(context.Background(), r)
+	for _, e := range report.Errors {
+		level.Warn(logger).Log("file", cfg.File, "line", e.Line, "err", e.Err)
+	}
+	if err != nil {
+		level.Error(logger).Log("exit", err)
+		return 1
+	}
+
+	level.Info(logger).Log("file", cfg.File, "imported", report.Imported, "failed", report.Failed)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// importConfig configures the import subcommand.
+type importConfig struct {
+	File      string
+	BatchSize int
+
+	Database *databaseConfig
+}
+
+// registerImportFlags registers the flags of the import subcommand on fs.
+func registerImportFlags(fs *flag.FlagSet) *importConfig {
+	c := importConfig{Database: registerDatabaseFlags(fs)}
+	fs.StringVar(&c.File, "file", "data/titanic.csv", "CSV file to import, - for the standard input")
+	fs.IntVar(&c.BatchSize, "batch.size", importer.DefaultBatchSize, "Number of passengers written per batch")
+	return &c
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,130 +11,302 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/postgres"
-	"github.com/qor/validations"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	titanic "gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/auth"
 	"gitlab.com/hyperd/titanic/cockroachdb"
+	"gitlab.com/hyperd/titanic/config"
+	"gitlab.com/hyperd/titanic/idempotency"
 	titanicsvc "gitlab.com/hyperd/titanic/implementation"
 	"gitlab.com/hyperd/titanic/inmemory"
 	"gitlab.com/hyperd/titanic/middleware"
+	"gitlab.com/hyperd/titanic/ratelimit"
+	"gitlab.com/hyperd/titanic/sqlite"
+	"gitlab.com/hyperd/titanic/tracing"
+	grpctransport "gitlab.com/hyperd/titanic/transport/grpc"
+	"gitlab.com/hyperd/titanic/transport/grpc/pb"
 	httptransport "gitlab.com/hyperd/titanic/transport/http"
 	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	var (
-		httpAddr     = flag.String("http.addr", ":3000", "HTTP listen address")
-		httpsAddr    = flag.String("https.addr", ":8443", "HTTPS listen address")
-		databaseType = flag.String("database.type", "cockroachdb", "Database type")
-	)
-	flag.Parse()
-
-	var logger log.Logger
-	{
-		logger = log.NewLogfmtLogger(os.Stderr)
-		logger = log.NewSyncLogger(logger)
-		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
-		logger = log.With(logger, "caller", log.DefaultCaller)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "purge":
+			os.Exit(runPurge(os.Args[2:]))
+		case "apikey":
+			os.Exit(runAPIKey(os.Args[2:]))
+		}
+	}
 
-		logger = log.With(logger,
-			"svc", "titanic",
-			"ts", log.DefaultTimestampUTC,
-			"caller", log.DefaultCaller,
-		)
+	cfg := registerServerFlags(flag.CommandLine)
+	logger := newLogger()
+	if err := config.Parse(flag.CommandLine, os.Args[1:], otherFlags("titanic")...); err != nil {
+		level.Error(logger).Log("exit", err)
+		os.Exit(2)
+	}
+	if err := cfg.validate(); err != nil {
+		level.Error(logger).Log("exit", err)
+		os.Exit(2)
 	}
 
 	level.Info(logger).Log("msg", "service started")
 
 	defer level.Info(logger).Log("msg", "service ended")
 
-	selectedBackend := *databaseType // safe to dereference the *string
-	isInMemory := (selectedBackend == "inmemory")
+	var tracer *tracing.Tracer
+	{
+		if cfg.TracingExporter == "stdout" {
+			tracer = tracing.NewTracer(tracing.NewWriterExporter(os.Stdout))
+		}
+	}
 
-	var db *gorm.DB
+	var authenticator *auth.Authenticator
 	{
-		if !isInMemory {
+		if cfg.AuthKeys == "" && cfg.AuthAPIKeys == "" {
+			level.Warn(logger).Log("msg", "authentication disabled, the API is open to anyone")
+		} else {
+			authenticator = &auth.Authenticator{}
 			var err error
-
-			const addr = "postgresql://d4gh0s7@roach1:26257/titanic?sslmode=disable"
-
-			db, err = gorm.Open("postgres", addr)
-
-			if err != nil {
-				level.Error(logger).Log("exit", err)
-				os.Exit(-1)
+			if cfg.AuthKeys != "" {
+				if authenticator.Tokens, err = auth.LoadVerifier(cfg.AuthKeys); err != nil {
+					level.Error(logger).Log("exit", err)
+					os.Exit(-1)
+				}
+			}
+			if cfg.AuthAPIKeys != "" {
+				if authenticator.Keys, err = auth.NewFileKeyStore(cfg.AuthAPIKeys); err != nil {
+					level.Error(logger).Log("exit", err)
+					os.Exit(-1)
+				}
 			}
-			defer db.Close()
-
-			// Set to `true` and GORM will print out all DB queries.
-			db.LogMode(true)
-
-			// Disable table name's pluralization globally
-			db.SingularTable(true)
-			db.AutoMigrate(&titanic.People{})
-
-			// Validations uses GORM callbacks to handle validations
-			validations.RegisterCallbacks(db)
 		}
 	}
 
-	var svc titanic.Service
+	var limiter *ratelimit.Limiter
 	{
-		if isInMemory {
-			level.Info(logger).Log("backend", "database", "type", "inmemory")
-
-			repository, err := inmemory.NewInmemService(logger)
+		if cfg.RateLimitRate > 0 {
+			costs, err := ratelimit.ParseCosts(cfg.RateLimitCosts)
 			if err != nil {
 				level.Error(logger).Log("exit", err)
 				os.Exit(-1)
 			}
-			svc = titanicsvc.NewService(repository, logger)
-		} else {
-			level.Info(logger).Log("backend", "database", "type", "cockroachdb")
-
-			repository, err := cockroachdb.New(db, logger)
+			proxies, err := ratelimit.ParseProxies(cfg.RateLimitProxies)
 			if err != nil {
 				level.Error(logger).Log("exit", err)
 				os.Exit(-1)
 			}
-			svc = titanicsvc.NewService(repository, logger)
+			limiter = ratelimit.NewLimiter(cfg.RateLimitRate, cfg.RateLimitBurst, costs, proxies)
+		}
+	}
+
+	var idempotencyKeys idempotency.Store
+	{
+		if cfg.IdempotencyTTL > 0 {
+			idempotencyKeys = idempotency.NewInmemStore(cfg.IdempotencyTTL)
+		}
+	}
+
+	var svc titanic.Service
+	{
+		repository, closeRepository, err := newRepository(*cfg.Database, logger)
+		if err != nil {
+			level.Error(logger).Log("exit", err)
+			os.Exit(-1)
+		}
+		defer closeRepository()
+
+		svc = titanicsvc.NewService(repository, logger)
+		// Service middleware: Authorization
+		if authenticator != nil {
+			svc = middleware.AuthorizationMiddleware()(svc)
 		}
 		// Service middleware: Logging
 		svc = middleware.LoggingMiddleware(logger)(svc)
+		// Service middleware: Instrumenting
+		svc = newInstrumentingMiddleware()(svc)
+		// Service middleware: Tracing
+		svc = middleware.TracingMiddleware()(svc)
 
 	}
 
 	var h http.Handler
 	{
-		h = httptransport.MakeHTTPHandler(svc, log.With(logger, "component", "HTTP"))
+		h = httptransport.MakeHTTPHandler(svc, log.With(logger, "component", "HTTP"), tracer, authenticator, limiter, idempotencyKeys)
+	}
+
+	var g pb.TitanicServer
+	{
+		g = grpctransport.MakeGRPCServer(svc, log.With(logger, "component", "gRPC"), authenticator, limiter)
 	}
 
 	errs := make(chan error)
 	go func() {
-		c := make(chan os.Signal)
+		c := make(chan os.Signal, 1)
 		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
 	go func() {
-		logger.Log("transport", "HTTP", "addr", *httpAddr)
-		errs <- http.ListenAndServe(*httpAddr, h)
+		logger.Log("transport", "HTTP", "addr", cfg.HTTPAddr)
+		errs <- http.ListenAndServe(cfg.HTTPAddr, h)
 	}()
 
+	if cfg.HTTPSAddr != "" {
+		go func() {
+			var httpServer = http.Server{
+				Addr:    cfg.HTTPSAddr,
+				Handler: h,
+			}
+
+			var http2Server = http2.Server{}
+			_ = http2.ConfigureServer(&httpServer, &http2Server)
+			logger.Log("transport", "HTTPS", "addr", cfg.HTTPSAddr)
+			errs <- httpServer.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+		}()
+	}
+
 	go func() {
-		var httpServer = http.Server{
-			Addr:    *httpsAddr,
-			Handler: h,
+		grpcListener, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			errs <- err
+			return
 		}
+		defer grpcListener.Close()
 
-		var http2Server = http2.Server{}
-		_ = http2.ConfigureServer(&httpServer, &http2Server)
-		logger.Log("transport", "HTTPS", "addr", *httpsAddr)
-		errs <- httpServer.ListenAndServeTLS("/etc/tls/certs/tls.crt", "/etc/tls/certs/tls.key")
-		// errs <- http.ListenAndServeTLS(*httpsAddr, "./tls/tls.crt", "./tls/tls.key", h)
-
+		grpcServer := grpc.NewServer()
+		pb.RegisterTitanicServer(grpcServer, g)
+		logger.Log("transport", "gRPC", "addr", cfg.GRPCAddr)
+		errs <- grpcServer.Serve(grpcListener)
 	}()
 
 	logger.Log("exit", <-errs)
 }
+
+func newLogger() log.Logger {
+	var logger log.Logger
+	{
+		logger = log.NewLogfmtLogger(os.Stderr)
+		logger = log.NewSyncLogger(logger)
+		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+		logger = log.With(logger, "caller", log.DefaultCaller)
+
+		logger = log.With(logger,
+			"svc", "titanic",
+			"ts", log.DefaultTimestampUTC,
+			"caller", log.DefaultCaller,
+		)
+	}
+	return logger
+}
+
+// newInstrumentingMiddleware returns the instrumenting middleware, exposing
+// its metrics to Prometheus.
+func newInstrumentingMiddleware() middleware.Middleware {
+	fieldKeys := []string{"method"}
+	requestCount := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "hyperd",
+		Subsystem: "titanic",
+		Name:      "request_count",
+		Help:      "Number of requests received.",
+	}, fieldKeys)
+	errorCount := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "hyperd",
+		Subsystem: "titanic",
+		Name:      "error_count",
+		Help:      "Number of requests failed, by error.",
+	}, append(fieldKeys, "error"))
+	requestLatency := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "hyperd",
+		Subsystem: "titanic",
+		Name:      "request_latency_seconds",
+		Help:      "Total duration of requests in seconds.",
+		Buckets:   stdprometheus.DefBuckets,
+	}, fieldKeys)
+
+	return middleware.InstrumentingMiddleware(requestCount, errorCount, requestLatency)
+}
+
+// newRepository returns the repository backed by the configured database,
+// along with a function releasing its resources.
+func newRepository(c databaseConfig, logger log.Logger) (titanic.Repository, func(), error) {
+	if c.Type == "inmemory" {
+		if c.InmemoryDir == "" {
+			level.Info(logger).Log("backend", "database", "type", "inmemory")
+
+			repository, err := inmemory.NewInmemService(logger)
+			return repository, func() {}, err
+		}
+
+		level.Info(logger).Log("backend", "database", "type", "inmemory", "dir", c.InmemoryDir, "sync", c.InmemorySync)
+
+		repository, closeRepository, err := inmemory.NewDurableInmemService(logger, inmemory.Options{
+			Dir:              c.InmemoryDir,
+			Sync:             inmemory.SyncPolicy(c.InmemorySync),
+			SyncInterval:     c.InmemorySyncInterval,
+			SnapshotInterval: c.InmemorySnapshotInterval,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return repository, func() {
+			if err := closeRepository(); err != nil {
+				level.Error(logger).Log("backend", "database", "type", "inmemory", "err", err)
+			}
+		}, nil
+	}
+
+	if c.Type == "sqlite" {
+		level.Info(logger).Log("backend", "database", "type", "sqlite", "path", c.Path)
+
+		db, err := sqlite.Open(c.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		repository, err := sqlite.New(db, logger)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return repository, func() { db.Close() }, nil
+	}
+
+	level.Info(logger).Log("backend", "database", "type", "cockroachdb")
+
+	dsn, err := c.dataSource()
+	if err != nil {
+		return nil, nil, err
+	}
+	timeouts, err := cockroachdb.ParseTimeouts(c.Timeouts)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := gorm.Open("postgres", dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	db.DB().SetMaxOpenConns(c.MaxOpenConns)
+	db.DB().SetMaxIdleConns(c.MaxIdleConns)
+	db.DB().SetConnMaxLifetime(c.ConnMaxLifetime)
+
+	// Set to `true` and GORM will print out all DB queries.
+	db.LogMode(true)
+
+	// Disable table name's pluralization globally
+	db.SingularTable(true)
+	db.AutoMigrate(&titanic.People{})
+
+	repository, err := cockroachdb.New(db, logger, timeouts)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return repository, func() { db.Close() }, nil
+}
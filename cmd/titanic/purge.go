@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"gitlab.com/hyperd/titanic/config"
+)
+
+// runPurge implements the purge subcommand, permanently removing from the
+// selected database the passengers deleted before the cutoff:
+//
+//	titanic purge -before 2020-05-01T00:00:00Z -database.type cockroachdb
+func runPurge(args []string) int {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	cfg := registerPurgeFlags(fs)
+	logger := newLogger()
+	if err := config.Parse(fs, args, otherFlags("purge")...); err != nil {
+		level.Error(logger).Log("exit", err)
+		return 2
+	}
+	if err := cfg.Database.validate(); err != nil {
+		level.Error(logger).Log("exit", err)
+		return 2
+	}
+
+	cutoff, err := time.Parse(time.RFC3339, cfg.Before)
+	if err != nil {
+		level.Error(logger).Log("exit", "-before must be an RFC 3339 time", "err", err)
+		return 1
+	}
+
+	repository, closeRepository, err := newRepository(*cfg.Database, logger)
+	if err != nil {
+		level.Error(logger).Log("exit", err)
+		return 1
+	}
+	defer closeRepository()
+
+	purged, err := repository.PurgePeople(context.Background(), cutoff)
+	if err != nil {
+		level.Error(logger).Log("exit", err)
+		return 1
+	}
+
+	level.Info(logger).Log("before", cutoff.Format(time.RFC3339), "purged", purged)
+	return 0
+}
+
+// purgeConfig configures the purge subcommand.
+type purgeConfig struct {
+	Before string
+
+	Database *databaseConfig
+}
+
+// registerPurgeFlags registers the flags of the purge subcommand on fs.
+func registerPurgeFlags(fs *flag.FlagSet) *purgeConfig {
+	c := purgeConfig{Database: registerDatabaseFlags(fs)}
+	fs.StringVar(&c.Before, "before", "", "Purge the passengers deleted before this time (RFC 3339)")
+	return &c
+}
@@ -2,143 +2,408 @@ package cockroachdb
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/gormstore"
 )
 
 type repository struct {
-	db     *gorm.DB
-	logger log.Logger
+	db       *gorm.DB
+	logger   log.Logger
+	timeouts map[string]time.Duration
 }
 
-// New returns a concrete repository backed by CockroachDB
-func New(db *gorm.DB, logger log.Logger) (titanic.Repository, error) {
+// New returns a concrete repository backed by CockroachDB. The statements
+// are traced as children of the span carried by the context of each call,
+// and the changes are recorded in the people_audit table, created if needed.
+// The statements of each call are canceled once its context is done, or
+// once the timeout of the method, if any, has elapsed; the timeouts default
+// to DefaultTimeouts if nil.
+func New(db *gorm.DB, logger log.Logger, timeouts map[string]time.Duration) (titanic.Repository, error) {
+	registerTracing(db)
+
+	if err := db.AutoMigrate(&gormstore.ChangeRow{}).Error; err != nil {
+		return nil, err
+	}
+
+	if timeouts == nil {
+		timeouts = DefaultTimeouts
+	}
+
 	// return  repository
 	return &repository{
-		db:     db,
-		logger: log.With(logger, "rep", "cockroachdb"),
+		db:       db,
+		logger:   log.With(logger, "rep", "cockroachdb"),
+		timeouts: timeouts,
 	}, nil
 }
 
-// Functions of type `txnFunc` are passed as arguments to our
-// `runTransaction` wrapper that handles transaction retries for us
-// (see implementation below).
-type txnFunc func(*gorm.DB) error
-
-// This function is used for testing the transaction retry loop.  It
-// can be deleted from production code.
-var forceRetryLoop txnFunc = func(db *gorm.DB) error {
-
-	// The first statement in a transaction can be retried transparently
-	// on the server, so we need to add a dummy statement so that our
-	// force_retry statement isn't the first one.
-	if err := db.Exec("SELECT now()").Error; err != nil {
-		return err
-	}
-	// Used to force a transaction retry.  Can only be run as the
-	// 'root' user.
-	if err := db.Exec("SELECT crdb_internal.force_retry('1s'::INTERVAL)").Error; err != nil {
-		return err
-	}
-	return nil
-}
+func (repo *repository) PostPeople(ctx context.Context, people titanic.People) (_ string, err error) {
+	ctx, db, done := repo.begin(ctx, "PostPeople")
+	defer func() { err = done(err) }()
 
-func (repo *repository) PostPeople(ctx context.Context, people titanic.People) (string, error) {
 	// Run a transaction to sync the query model.
 	id := uuid.New()
-	people.ID = id
-
-	if err := repo.db.Create(&titanic.People{
-		ID:                    people.ID,
-		Survived:              people.Survived,
-		Pclass:                people.Pclass,
-		Name:                  people.Name,
-		Sex:                   people.Sex,
-		Age:                   people.Age,
-		SiblingsSpousesAbroad: people.SiblingsSpousesAbroad,
-		ParentsChildrenAboard: people.ParentsChildrenAboard,
-		Fare:                  people.Fare}).Error; err != nil {
 
+	err = runTransaction(ctx, db, func(tx *gorm.DB) error {
+		created := titanic.People{
+			ID:                    id,
+			Survived:              people.Survived,
+			Pclass:                people.Pclass,
+			Name:                  people.Name,
+			Sex:                   people.Sex,
+			Age:                   people.Age,
+			SiblingsSpousesAbroad: people.SiblingsSpousesAbroad,
+			ParentsChildrenAboard: people.ParentsChildrenAboard,
+			Fare:                  people.Fare,
+			Version:               1}
+
+		if err := tx.Create(&created).Error; err != nil {
+			return err
+		}
+		return gormstore.RecordChange(ctx, tx, titanic.ActionCreate, id, nil, &created)
+	})
+	if err != nil {
 		return err.Error(), err
 	}
 
 	return id.String(), nil
 }
 
-func (repo *repository) GetPeopleByID(ctx context.Context, id uuid.UUID) (titanic.People, error) {
+func (repo *repository) PostPeopleBatch(ctx context.Context, people []titanic.People) (_ []string, err error) {
+	ctx, db, done := repo.begin(ctx, "PostPeopleBatch")
+	defer func() { err = done(err) }()
+
+	ids := make([]string, len(people))
+
+	// The whole batch is written in a single transaction.
+	err = runTransaction(ctx, db, func(tx *gorm.DB) error {
+		for i, p := range people {
+			p.ID = uuid.New()
+			p.Version = 1
+			// The timestamps are set by GORM.
+			p.CreatedAt, p.UpdatedAt, p.DeletedAt = time.Time{}, time.Time{}, nil
+			if err := tx.Create(&p).Error; err != nil {
+				return err
+			}
+			if err := gormstore.RecordChange(ctx, tx, titanic.ActionCreate, p.ID, nil, &p); err != nil {
+				return err
+			}
+			ids[i] = p.ID.String()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (repo *repository) GetPeopleByID(ctx context.Context, id uuid.UUID) (_ titanic.People, err error) {
+	ctx, db, done := repo.begin(ctx, "GetPeopleByID")
+	defer func() { err = done(err) }()
+
 	var people = titanic.People{}
 
-	if err := repo.db.Where("id = ?", id).First(&people).Error; err != nil {
+	err = runQuery(ctx, db, func(tx *gorm.DB) error {
+		return tx.Where("id = ?", id).First(&people).Error
+	})
+	if gorm.IsRecordNotFoundError(err) {
 		return people, titanic.ErrNotFound
 	}
 
-	return people, nil
+	return people, err
 }
 
-func (repo *repository) PutPeople(ctx context.Context, id uuid.UUID, people titanic.People) error {
-	tx := repo.db.Begin()
-	// Update multiple attributes with `struct`, will only update those changed & non blank fields
-	if err := tx.Model(&people).Updates(titanic.People{
-		ID:                    id,
-		Survived:              people.Survived,
-		Pclass:                people.Pclass,
-		Name:                  people.Name,
-		Sex:                   people.Sex,
-		Age:                   people.Age,
-		SiblingsSpousesAbroad: people.SiblingsSpousesAbroad,
-		ParentsChildrenAboard: people.ParentsChildrenAboard,
-		Fare:                  people.Fare,
-	}).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+func (repo *repository) PutPeople(ctx context.Context, id uuid.UUID, people titanic.People) (created bool, err error) {
+	ctx, db, done := repo.begin(ctx, "PutPeople")
+	defer func() { err = done(err) }()
+
+	// created is set by the last attempt of the transaction, the one
+	// committed.
+	err = runTransaction(ctx, db, func(tx *gorm.DB) error {
+		before, err := gormstore.Snapshot(tx, id)
+		if err != nil {
+			return err
+		}
+
+		bumped, err := bumpVersion(tx, id, people.Version)
+		if err != nil {
+			return err
+		}
 
-	tx.Commit()
+		created = !bumped
+		if created {
+			if people.Version != 0 {
+				return titanic.ErrVersionConflict // a missing passenger matches no version
+			}
+			if before != nil {
+				return titanic.ErrNotFound // a deleted passenger must be restored first
+			}
+			// PUT can create
+			created := people
+			created.ID = id
+			created.Version = 1
+			created.CreatedAt, created.UpdatedAt, created.DeletedAt = time.Time{}, time.Time{}, nil
+			if err := tx.Create(&created).Error; err != nil {
+				return err
+			}
+		} else {
+			// Update multiple attributes with `struct`, will only update those changed & non blank fields
+			if err := tx.Model(&titanic.People{}).Where("id = ?", id).Updates(titanic.People{
+				Survived:              people.Survived,
+				Pclass:                people.Pclass,
+				Name:                  people.Name,
+				Sex:                   people.Sex,
+				Age:                   people.Age,
+				SiblingsSpousesAbroad: people.SiblingsSpousesAbroad,
+				ParentsChildrenAboard: people.ParentsChildrenAboard,
+				Fare:                  people.Fare,
+			}).Error; err != nil {
+				return err
+			}
+		}
 
-	return nil
+		return gormstore.RecordUpdate(ctx, tx, titanic.ActionPut, id, before)
+	})
+	return created, err
 }
 
-func (repo *repository) PatchPeople(ctx context.Context, id uuid.UUID, people titanic.People) error {
-	tx := repo.db.Begin()
-	if err := tx.Model(&people).Where("id = ?", id).Updates(titanic.People{
-		Survived:              people.Survived,
-		Pclass:                people.Pclass,
-		Name:                  people.Name,
-		Sex:                   people.Sex,
-		Age:                   people.Age,
-		SiblingsSpousesAbroad: people.SiblingsSpousesAbroad,
-		ParentsChildrenAboard: people.ParentsChildrenAboard,
-		Fare:                  people.Fare,
-	}).Error; err != nil {
-		tx.Rollback()
-		return err
+func (repo *repository) PatchPeople(ctx context.Context, id uuid.UUID, people titanic.People) (err error) {
+	ctx, db, done := repo.begin(ctx, "PatchPeople")
+	defer func() { err = done(err) }()
+
+	return runTransaction(ctx, db, func(tx *gorm.DB) error {
+		before, err := gormstore.Snapshot(tx, id)
+		if err != nil {
+			return err
+		}
+
+		bumped, err := bumpVersion(tx, id, people.Version)
+		if err != nil {
+			return err
+		}
+		if !bumped {
+			if people.Version != 0 {
+				return titanic.ErrVersionConflict
+			}
+			return titanic.ErrNotFound // PATCH = update existing, don't create
+		}
+
+		if err := tx.Model(&titanic.People{}).Where("id = ?", id).Updates(titanic.People{
+			Survived:              people.Survived,
+			Pclass:                people.Pclass,
+			Name:                  people.Name,
+			Sex:                   people.Sex,
+			Age:                   people.Age,
+			SiblingsSpousesAbroad: people.SiblingsSpousesAbroad,
+			ParentsChildrenAboard: people.ParentsChildrenAboard,
+			Fare:                  people.Fare,
+		}).Error; err != nil {
+			return err
+		}
+
+		return gormstore.RecordUpdate(ctx, tx, titanic.ActionPatch, id, before)
+	})
+}
+
+func (repo *repository) DeletePeople(ctx context.Context, id uuid.UUID, version int) (_ string, err error) {
+	ctx, db, done := repo.begin(ctx, "DeletePeople")
+	defer func() { err = done(err) }()
+
+	return id.String(), runTransaction(ctx, db, func(tx *gorm.DB) error {
+		before, err := gormstore.Snapshot(tx, id)
+		if err != nil {
+			return err
+		}
+
+		scope := tx.Model(&titanic.People{}).Where("id = ?", id)
+		if version > 0 {
+			scope = scope.Where("version = ?", version)
+		}
+
+		// The row is only marked as deleted, until purged, as a new version
+		// of the passenger: the versions read before no longer match.
+		deleted := scope.UpdateColumns(map[string]interface{}{
+			"deleted_at": gorm.NowFunc(),
+			"version":    gorm.Expr("version + 1"),
+		})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		if deleted.RowsAffected == 0 {
+			if version != 0 {
+				return titanic.ErrVersionConflict
+			}
+			return titanic.ErrNotFound
+		}
+
+		return gormstore.RecordUpdate(ctx, tx, titanic.ActionDelete, id, before)
+	})
+}
+
+func (repo *repository) RestorePeople(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, db, done := repo.begin(ctx, "RestorePeople")
+	defer func() { err = done(err) }()
+
+	return runTransaction(ctx, db, func(tx *gorm.DB) error {
+		before, err := gormstore.Snapshot(tx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return titanic.ErrNotFound
+		}
+		if before.DeletedAt == nil {
+			// Restoring a passenger that isn't deleted has no effect.
+			return nil
+		}
+
+		if err := tx.Unscoped().Model(&titanic.People{}).Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{
+				"deleted_at": gorm.Expr("NULL"),
+				"version":    gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+
+		return gormstore.RecordUpdate(ctx, tx, titanic.ActionRestore, id, before)
+	})
+}
+
+func (repo *repository) PurgePeople(ctx context.Context, before time.Time) (_ int, err error) {
+	ctx, db, done := repo.begin(ctx, "PurgePeople")
+	defer func() { err = done(err) }()
+
+	// The history of the purged passengers is kept.
+	var n int
+	err = runTransaction(ctx, db, func(tx *gorm.DB) error {
+		purged := tx.Unscoped().Where("deleted_at < ?", before).Delete(&titanic.People{})
+		n = int(purged.RowsAffected)
+		return purged.Error
+	})
+	if err != nil {
+		return 0, err
 	}
+	return n, nil
+}
 
-	tx.Commit()
+// bumpVersion increments the version of the passenger, provided it is at the
+// given version, if any, and reports whether it did. It also locks the row
+// for the rest of the transaction.
+func bumpVersion(tx *gorm.DB, id uuid.UUID, version int) (bool, error) {
+	scope := tx.Model(&titanic.People{}).Where("id = ?", id)
+	if version > 0 {
+		scope = scope.Where("version = ?", version)
+	}
 
-	return nil
+	bumped := scope.UpdateColumns(map[string]interface{}{
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": gorm.NowFunc(),
+	})
+	return bumped.RowsAffected > 0, bumped.Error
 }
 
-func (repo *repository) DeletePeople(ctx context.Context, id uuid.UUID) (string, error) {
-	tx := repo.db.Begin()
+func (repo *repository) GetPeopleHistory(ctx context.Context, id uuid.UUID) (_ []titanic.Change, err error) {
+	ctx, db, done := repo.begin(ctx, "GetPeopleHistory")
+	defer func() { err = done(err) }()
 
-	if err := tx.Where("id = ?", id).Delete(&titanic.People{}).Error; err != nil {
-		tx.Rollback()
-		return id.String(), err
+	var history []titanic.Change
+	err = runQuery(ctx, db, func(tx *gorm.DB) (err error) {
+		history, err = gormstore.History(tx, id)
+		return err
+	})
+	return history, err
+}
+
+func (repo *repository) GetPeople(ctx context.Context, q titanic.PeopleQuery) (_ titanic.PeoplePage, err error) {
+	ctx, db, done := repo.begin(ctx, "GetPeople")
+	defer func() { err = done(err) }()
+
+	var page = titanic.PeoplePage{People: []titanic.People{}}
+
+	// The count and the page are read from the same snapshot.
+	err = runQuery(ctx, db, func(tx *gorm.DB) error {
+		if q.IncludeDeleted {
+			tx = tx.Unscoped()
+		}
+		filtered := gormstore.FilterPeople(tx, q.Filter)
+
+		if err := filtered.Model(&titanic.People{}).Count(&page.Total).Error; err != nil {
+			return err
+		}
+
+		// Fetch one extra row to find out whether there is a next page.
+		scope := gormstore.SortPeople(filtered, q.Sort).Limit(q.Limit + 1)
+
+		if q.Cursor != "" {
+			c, err := q.DecodeCursor()
+			if err != nil {
+				return err
+			}
+			scope = gormstore.AfterCursor(scope, c, q.Sort)
+		} else if q.Offset > 0 {
+			scope = scope.Offset(q.Offset)
+		}
+
+		return scope.Find(&page.People).Error
+	})
+	if err != nil {
+		return page, err
 	}
 
-	tx.Commit()
+	if len(page.People) > q.Limit {
+		page.People = page.People[:q.Limit]
+		page.NextCursor = titanic.NewCursor(page.People[q.Limit-1], q.Sort).String()
+	}
 
-	return id.String(), nil
+	return page, nil
 }
 
-func (repo *repository) GetPeople(ctx context.Context) ([]titanic.People, error) {
-	var people []titanic.People
+func (repo *repository) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) (err error) {
+	ctx, db, done := repo.begin(ctx, "StreamPeople")
+	defer func() { err = done(err) }()
+
+	return runQuery(ctx, db, func(tx *gorm.DB) error {
+		if q.IncludeDeleted {
+			tx = tx.Unscoped()
+		}
 
-	repo.db.Find(&people)
+		// Scan the rows one at a time, rather than loading the whole result set.
+		rows, err := gormstore.SortPeople(gormstore.FilterPeople(tx, q.Filter), q.Sort).Model(&titanic.People{}).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
 
-	return people, nil
+		for rows.Next() {
+			var people titanic.People
+			if err := tx.ScanRows(rows, &people); err != nil {
+				return err
+			}
+			if err := fn(people); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
+
+func (repo *repository) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (_ titanic.Statistics, err error) {
+	ctx, db, done := repo.begin(ctx, "GetStatistics")
+	defer func() { err = done(err) }()
+
+	// The aggregates and the medians are read from the same snapshot.
+	var stats titanic.Statistics
+	err = runQuery(ctx, db, func(tx *gorm.DB) (err error) {
+		stats, err = gormstore.Statistics(tx, q)
+		return err
+	})
+	return stats, err
 }
@@ -0,0 +1,141 @@
+package cockroachdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"gitlab.com/hyperd/titanic"
+)
+
+// DefaultTimeouts are the default timeouts of the repository methods, by
+// method name. The statements of a method are canceled once its timeout has
+// elapsed, or once the context of the call is done, whichever comes first;
+// a zero timeout leaves the statements bound to the context alone, e.g. the
+// exports streamed for as long as the client reads them.
+var DefaultTimeouts = map[string]time.Duration{
+	"PostPeople":       5 * time.Second,
+	"PostPeopleBatch":  30 * time.Second,
+	"GetPeopleByID":    5 * time.Second,
+	"PutPeople":        5 * time.Second,
+	"PatchPeople":      5 * time.Second,
+	"DeletePeople":     5 * time.Second,
+	"RestorePeople":    5 * time.Second,
+	"PurgePeople":      5 * time.Minute,
+	"GetPeopleHistory": 5 * time.Second,
+	"GetPeople":        10 * time.Second,
+	"StreamPeople":     0,
+	"GetStatistics":    30 * time.Second,
+}
+
+// ParseTimeouts parses comma separated method=timeout pairs, e.g.
+// "GetPeople=2s,PurgePeople=10m", into the timeouts of the methods, the
+// methods left out keeping their default timeout.
+func ParseTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(DefaultTimeouts))
+	for method, timeout := range DefaultTimeouts {
+		timeouts[method] = timeout
+	}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid timeout %q", pair)
+		}
+		method := strings.TrimSpace(kv[0])
+		if _, ok := DefaultTimeouts[method]; !ok {
+			return nil, fmt.Errorf("unknown method %q", method)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(kv[1]))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid timeout %q", pair)
+		}
+		timeouts[method] = timeout
+	}
+	return timeouts, nil
+}
+
+// begin starts a call to a repository method: it bounds ctx by the timeout
+// of the method and starts its span, as startSpan does. The returned done
+// function ends the call, and must be passed its error, which it returns
+// as ErrCanceled or ErrTimeout if the call failed because ctx was done.
+func (repo *repository) begin(ctx context.Context, method string) (context.Context, *gorm.DB, func(error) error) {
+	cancel := func() {}
+	if timeout := repo.timeouts[method]; timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	db, span := repo.startSpan(ctx, method)
+
+	return ctx, db, func(err error) error {
+		err = contextError(ctx, err)
+		span.Finish(err)
+		cancel()
+		return err
+	}
+}
+
+// queryCanceledCode is the SQLSTATE of the statements canceled by the
+// server, e.g. once their statement_timeout has elapsed.
+const queryCanceledCode = "57014"
+
+// contextError returns ErrCanceled or ErrTimeout in place of err if ctx is
+// done, as the statements fail with the errors of the driver when canceled,
+// and ErrTimeout in place of the statements canceled by their
+// statement_timeout, which may elapse just before ctx is.
+func contextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch ctx.Err() {
+	case context.Canceled:
+		return titanic.ErrCanceled
+	case context.DeadlineExceeded:
+		return titanic.ErrTimeout
+	}
+	if hasCode(err, queryCanceledCode) {
+		return titanic.ErrTimeout
+	}
+	return err
+}
+
+// setStatementTimeout sets the statement_timeout of the transaction tx to
+// the time left until the deadline of ctx, if any. GORM runs the statements
+// without their context, and the transaction waits for the statement
+// running to end before being rolled back once ctx is done: the server alone
+// can cancel a slow statement on time.
+func setStatementTimeout(ctx context.Context, tx *gorm.DB) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	left := time.Until(deadline)
+	if left <= 0 {
+		return context.DeadlineExceeded
+	}
+	// A zero timeout would disable it, and SET takes no placeholders.
+	ms := (left + time.Millisecond - 1) / time.Millisecond
+	return tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = '%dms'", ms)).Error
+}
+
+// runQuery runs fn in a read-only transaction bound to ctx, so that its
+// statements are canceled along with ctx, and time out with it.
+func runQuery(ctx context.Context, db *gorm.DB, fn txnFunc) (err error) {
+	tx := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := setStatementTimeout(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
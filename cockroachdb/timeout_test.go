@@ -0,0 +1,181 @@
+package cockroachdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	"gitlab.com/hyperd/titanic"
+)
+
+func TestContextError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	driverErr := errors.New("pq: canceling statement due to user request")
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want error
+	}{
+		{"success", canceled, nil, nil},
+		{"live context", context.Background(), driverErr, driverErr},
+		{"canceled", canceled, driverErr, titanic.ErrCanceled},
+		{"timed out", expired, driverErr, titanic.ErrTimeout},
+		{"business error", context.Background(), titanic.ErrNotFound, titanic.ErrNotFound},
+		{"statement timeout", context.Background(), &pq.Error{Code: "57014"}, titanic.ErrTimeout},
+		{"statement timeout among others", context.Background(), gorm.Errors{errors.New("invalid value"), &pq.Error{Code: "57014"}}, titanic.ErrTimeout},
+	} {
+		if got := contextError(tc.ctx, tc.err); got != tc.want {
+			t.Errorf("%s: contextError: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseTimeouts(t *testing.T) {
+	timeouts, err := ParseTimeouts(" GetPeople=2s, PurgePeople=10m,StreamPeople=0s ")
+	if err != nil {
+		t.Fatalf("ParseTimeouts: %v", err)
+	}
+	for method, want := range map[string]time.Duration{
+		"GetPeople":     2 * time.Second,
+		"PurgePeople":   10 * time.Minute,
+		"StreamPeople":  0,
+		"GetPeopleByID": DefaultTimeouts["GetPeopleByID"],
+	} {
+		if got := timeouts[method]; got != want {
+			t.Errorf("%s: got %v, want %v", method, got, want)
+		}
+	}
+	if len(timeouts) != len(DefaultTimeouts) {
+		t.Errorf("got %d timeouts, want %d", len(timeouts), len(DefaultTimeouts))
+	}
+
+	for _, s := range []string{"GetPeople", "GetPeople=fast", "GetPeople=-1s", "Unknown=1s"} {
+		if _, err := ParseTimeouts(s); err == nil {
+			t.Errorf("ParseTimeouts(%q): got no error", s)
+		}
+	}
+}
+
+// slowDriver stands for a server running pg_sleep for as long as asked,
+// unless the statement_timeout of the transaction elapses first: as GORM
+// runs the statements without their context, nothing else interrupts them.
+type slowDriver struct{}
+
+func (slowDriver) Open(string) (driver.Conn, error) { return &slowConn{}, nil }
+
+type slowConn struct {
+	mtx     sync.Mutex
+	timeout time.Duration
+}
+
+func (c *slowConn) Prepare(query string) (driver.Stmt, error) { return slowStmt{c, query}, nil }
+func (c *slowConn) Close() error                              { return nil }
+func (c *slowConn) Begin() (driver.Tx, error)                 { return c, nil }
+
+func (c *slowConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) { return c, nil }
+
+func (c *slowConn) Commit() error   { return c.end() }
+func (c *slowConn) Rollback() error { return c.end() }
+
+// end ends the transaction, and the statement_timeout set in it.
+func (c *slowConn) end() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.timeout = 0
+	return nil
+}
+
+type slowStmt struct {
+	conn  *slowConn
+	query string
+}
+
+func (s slowStmt) Close() error  { return nil }
+func (s slowStmt) NumInput() int { return -1 }
+
+func (s slowStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("unsupported query")
+}
+
+func (s slowStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.conn.mtx.Lock()
+	defer s.conn.mtx.Unlock()
+	var ms, seconds int
+	if _, err := fmt.Sscanf(s.query, "SET LOCAL statement_timeout = '%dms'", &ms); err == nil {
+		s.conn.timeout = time.Duration(ms) * time.Millisecond
+		return driver.RowsAffected(0), nil
+	}
+	if _, err := fmt.Sscanf(s.query, "SELECT pg_sleep(%d)", &seconds); err == nil {
+		d := time.Duration(seconds) * time.Second
+		if s.conn.timeout > 0 && s.conn.timeout < d {
+			time.Sleep(s.conn.timeout)
+			return nil, &pq.Error{Code: queryCanceledCode, Message: "query execution canceled due to statement timeout"}
+		}
+		time.Sleep(d)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func init() {
+	sql.Register("slow", slowDriver{})
+}
+
+func TestStatementTimeout(t *testing.T) {
+	sqlDB, err := sql.Open("slow", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open("postgres", sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	testSlowStatement(t, db.LogMode(false))
+}
+
+func TestStatementTimeoutOnCockroachDB(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	testSlowStatement(t, db)
+}
+
+// testSlowStatement checks that a statement outliving the deadline of its
+// context is canceled on time, in a transaction as in a query.
+func testSlowStatement(t *testing.T, db *gorm.DB) {
+	for _, tc := range []struct {
+		name string
+		run  func(context.Context, *gorm.DB, txnFunc) error
+	}{
+		{"runQuery", runQuery},
+		{"runTransaction", runTransaction},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		start := time.Now()
+		err := tc.run(ctx, db, func(tx *gorm.DB) error {
+			return tx.Exec("SELECT pg_sleep(10)").Error
+		})
+		elapsed := time.Since(start)
+		err = contextError(ctx, err)
+		cancel()
+
+		if err != titanic.ErrTimeout {
+			t.Errorf("%s: got %v, want %v", tc.name, err, titanic.ErrTimeout)
+		}
+		if elapsed > 2*time.Second {
+			t.Errorf("%s: returned after %v, want once the statement times out", tc.name, elapsed)
+		}
+	}
+
+}
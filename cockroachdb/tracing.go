@@ -0,0 +1,71 @@
+package cockroachdb
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+	"gitlab.com/hyperd/titanic/tracing"
+)
+
+// Keys of the span values set on the gorm handles and scopes.
+const (
+	spanKey          = "titanic:span"
+	statementSpanKey = "titanic:statement_span"
+)
+
+// startSpan starts the span of a repository method, as a child of the span
+// carried by ctx. The returned database handle traces each of its
+// statements as a child of the method span.
+func (repo *repository) startSpan(ctx context.Context, method string) (*gorm.DB, *tracing.Span) {
+	_, span := tracing.StartSpan(ctx, "cockroachdb."+method, "db.system", "cockroachdb")
+	if span == nil {
+		return repo.db, nil
+	}
+	return repo.db.Set(spanKey, span), span
+}
+
+// registerTracing registers the gorm callbacks tracing the statements run
+// by the handles returned by startSpan.
+func registerTracing(db *gorm.DB) {
+	callbacks := db.Callback()
+	if callbacks.Query().Get("titanic:trace_query") != nil {
+		return
+	}
+
+	callbacks.Create().Before("gorm:create").Register("titanic:trace_create", startStatement("gorm.create"))
+	callbacks.Create().After("gorm:create").Register("titanic:end_trace_create", endStatement)
+	callbacks.Query().Before("gorm:query").Register("titanic:trace_query", startStatement("gorm.query"))
+	callbacks.Query().After("gorm:query").Register("titanic:end_trace_query", endStatement)
+	callbacks.Update().Before("gorm:update").Register("titanic:trace_update", startStatement("gorm.update"))
+	callbacks.Update().After("gorm:update").Register("titanic:end_trace_update", endStatement)
+	callbacks.Delete().Before("gorm:delete").Register("titanic:trace_delete", startStatement("gorm.delete"))
+	callbacks.Delete().After("gorm:delete").Register("titanic:end_trace_delete", endStatement)
+	callbacks.RowQuery().Before("gorm:row_query").Register("titanic:trace_row_query", startStatement("gorm.row_query"))
+	callbacks.RowQuery().After("gorm:row_query").Register("titanic:end_trace_row_query", endStatement)
+}
+
+func startStatement(name string) func(*gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		v, ok := scope.Get(spanKey)
+		if !ok {
+			return
+		}
+		parent, _ := v.(*tracing.Span)
+		ctx := tracing.ContextWithSpan(context.Background(), parent)
+		_, span := tracing.StartSpan(ctx, name, "db.system", "cockroachdb", "db.table", scope.TableName())
+		scope.InstanceSet(statementSpanKey, span)
+	}
+}
+
+func endStatement(scope *gorm.Scope) {
+	v, ok := scope.InstanceGet(statementSpanKey)
+	if !ok {
+		return
+	}
+	span, _ := v.(*tracing.Span)
+	span.SetAttributes("db.statement", scope.SQL, "db.rows_affected", scope.DB().RowsAffected)
+	if err := scope.DB().Error; err != gorm.ErrRecordNotFound {
+		span.SetError(err)
+	}
+	span.End()
+}
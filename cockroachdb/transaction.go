@@ -0,0 +1,128 @@
+package cockroachdb
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// Functions of type `txnFunc` are passed as arguments to our
+// `runTransaction` wrapper that handles transaction retries for us
+// (see implementation below).
+type txnFunc func(*gorm.DB) error
+
+// The retry budget of the transactions: the number of attempts, and the
+// backoff between them, doubling from the first to the longest one.
+var (
+	maxTxnAttempts    = 10
+	firstTxnBackoff   = 10 * time.Millisecond
+	longestTxnBackoff = time.Second
+)
+
+// retryableCode is the SQLSTATE of the serialization failures, telling the
+// client to retry the transaction.
+const retryableCode = "40001"
+
+// runTransaction runs fn in a transaction bound to ctx, following the client-side retry
+// protocol of CockroachDB: the transaction sets the cockroach_restart
+// savepoint, is committed by releasing it, and is rolled back to it, and fn
+// run again, after a serialization failure of fn or of the release, until
+// maxTxnAttempts attempts have failed. Any other error of fn rolls back the
+// transaction and is returned as is, as is the error of ctx if it is done
+// while waiting for the next attempt. The statements time out along with
+// ctx, as set by setStatementTimeout.
+//
+// As fn may run several times, it must not change the state outside of the
+// transaction, e.g. the variables it closes over, until it succeeds.
+func runTransaction(ctx context.Context, db *gorm.DB, fn txnFunc) (err error) {
+	tx := db.BeginTx(ctx, nil)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Set before the savepoint, so as not to be undone by the rollbacks to it.
+	if err := setStatementTimeout(ctx, tx); err != nil {
+		return err
+	}
+	if err := tx.Exec("SAVEPOINT cockroach_restart").Error; err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = fn(tx)
+		if err == nil {
+			// Releasing the savepoint commits the transaction in
+			// CockroachDB, and may fail with a serialization failure.
+			err = tx.Exec("RELEASE SAVEPOINT cockroach_restart").Error
+		}
+		if err == nil {
+			return tx.Commit().Error
+		}
+		if !retryable(err) || attempt >= maxTxnAttempts {
+			return err
+		}
+
+		if err := tx.Exec("ROLLBACK TO SAVEPOINT cockroach_restart").Error; err != nil {
+			return err
+		}
+		if err := sleep(ctx, txnBackoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d, unless ctx is done first, in which case it returns the
+// error of ctx.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryable tells whether err is a serialization failure, after which the
+// transaction should be retried.
+func retryable(err error) bool {
+	return hasCode(err, retryableCode)
+}
+
+// hasCode tells whether err is a database error with the given SQLSTATE,
+// including when GORM reports it along with the other errors of the
+// statement.
+func hasCode(err error, code pq.ErrorCode) bool {
+	if errs, ok := err.(gorm.Errors); ok {
+		for _, err := range errs {
+			if hasCode(err, code) {
+				return true
+			}
+		}
+		return false
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+// txnBackoff returns how long to wait before retrying the transaction after
+// the given failed attempt: a random duration between the half and the
+// whole of the backoff of the attempt, so that the conflicting transactions
+// don't retry in lockstep.
+func txnBackoff(attempt int) time.Duration {
+	d := longestTxnBackoff
+	if attempt < 32 && firstTxnBackoff<<uint(attempt-1) < d {
+		d = firstTxnBackoff << uint(attempt-1)
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
@@ -0,0 +1,208 @@
+package cockroachdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/lib/pq"
+	"gitlab.com/hyperd/titanic"
+)
+
+// testDSNEnv names the environment variable holding the connection string
+// of the CockroachDB database the tests run against, as the root user, which
+// alone can force the retries. The tests needing it are skipped otherwise,
+// e.g. against the cluster of test_docker_cockroachdb.bash:
+//
+//	TITANIC_TEST_DATABASE_DSN=postgresql://root@localhost:26257/defaultdb?sslmode=disable go test ./cockroachdb/
+const testDSNEnv = "TITANIC_TEST_DATABASE_DSN"
+
+// forceRetryLoop makes the transaction fail with a serialization failure,
+// until it is a second old.
+var forceRetryLoop txnFunc = func(db *gorm.DB) error {
+
+	// The first statement in a transaction can be retried transparently
+	// on the server, so we need to add a dummy statement so that our
+	// force_retry statement isn't the first one.
+	if err := db.Exec("SELECT now()").Error; err != nil {
+		return err
+	}
+	// Used to force a transaction retry.  Can only be run as the
+	// 'root' user.
+	if err := db.Exec("SELECT crdb_internal.force_retry('1s'::INTERVAL)").Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", testDSNEnv)
+	}
+	db, err := gorm.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// createTestTable creates a table of its own for the test, along with the
+// function dropping it.
+func createTestTable(t *testing.T, db *gorm.DB) (string, func()) {
+	table := fmt.Sprintf("txn_test_%d", time.Now().UnixNano())
+	if err := db.Exec("CREATE TABLE " + table + " (n INT)").Error; err != nil {
+		t.Fatal(err)
+	}
+	return table, func() { db.Exec("DROP TABLE " + table) }
+}
+
+func countRows(t *testing.T, db *gorm.DB, table string) int {
+	var n int
+	if err := db.Table(table).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestRunTransactionRetries(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	table, drop := createTestTable(t, db)
+	defer drop()
+
+	attempts := 0
+	err := runTransaction(context.Background(), db, func(tx *gorm.DB) error {
+		attempts++
+		if err := tx.Exec("INSERT INTO "+table+" (n) VALUES (?)", attempts).Error; err != nil {
+			return err
+		}
+		return forceRetryLoop(tx)
+	})
+	if err != nil {
+		t.Fatalf("runTransaction: %v", err)
+	}
+	if attempts < 2 {
+		t.Errorf("attempts: got %d, want at least 2", attempts)
+	}
+	// The rows inserted by the failed attempts are rolled back.
+	if n := countRows(t, db, table); n != 1 {
+		t.Errorf("rows: got %d, want 1", n)
+	}
+}
+
+func TestRunTransactionBudget(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	table, drop := createTestTable(t, db)
+	defer drop()
+
+	defer func(n int) { maxTxnAttempts = n }(maxTxnAttempts)
+	maxTxnAttempts = 2
+
+	attempts := 0
+	err := runTransaction(context.Background(), db, func(tx *gorm.DB) error {
+		attempts++
+		if err := tx.Exec("INSERT INTO "+table+" (n) VALUES (?)", attempts).Error; err != nil {
+			return err
+		}
+		return forceRetryLoop(tx)
+	})
+	if !retryable(err) {
+		t.Fatalf("runTransaction: got %v, want a serialization failure", err)
+	}
+	if attempts != maxTxnAttempts {
+		t.Errorf("attempts: got %d, want %d", attempts, maxTxnAttempts)
+	}
+	if n := countRows(t, db, table); n != 0 {
+		t.Errorf("rows: got %d, want 0", n)
+	}
+}
+
+func TestRunTransactionRollsBack(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	table, drop := createTestTable(t, db)
+	defer drop()
+
+	attempts := 0
+	err := runTransaction(context.Background(), db, func(tx *gorm.DB) error {
+		attempts++
+		if err := tx.Exec("INSERT INTO "+table+" (n) VALUES (?)", attempts).Error; err != nil {
+			return err
+		}
+		return titanic.ErrNotFound
+	})
+	if err != titanic.ErrNotFound {
+		t.Fatalf("runTransaction: got %v, want %v", err, titanic.ErrNotFound)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts: got %d, want 1", attempts)
+	}
+	if n := countRows(t, db, table); n != 0 {
+		t.Errorf("rows: got %d, want 0", n)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "40001"}, true},
+		{fmt.Errorf("commit: %w", &pq.Error{Code: "40001"}), true},
+		{gorm.Errors{errors.New("invalid value"), &pq.Error{Code: "40001"}}, true},
+		{&pq.Error{Code: "23505"}, false},
+		{gorm.Errors{&pq.Error{Code: "23505"}, errors.New("invalid value")}, false},
+		{titanic.ErrVersionConflict, false},
+		{errors.New("connection refused"), false},
+		{nil, false},
+	} {
+		if got := retryable(tc.err); got != tc.want {
+			t.Errorf("retryable(%v): got %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestTxnBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{
+		1:   firstTxnBackoff,
+		2:   2 * firstTxnBackoff,
+		3:   4 * firstTxnBackoff,
+		20:  longestTxnBackoff,
+		100: longestTxnBackoff,
+	} {
+		for i := 0; i < 100; i++ {
+			if got := txnBackoff(attempt); got < want/2 || got > want {
+				t.Fatalf("txnBackoff(%d): got %v, want between %v and %v", attempt, got, want/2, want)
+			}
+		}
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("sleep: got %v, want nil", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := sleep(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("sleep: got %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("sleep: returned after %v, want at once", elapsed)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := sleep(ctx, time.Hour); err != context.DeadlineExceeded {
+		t.Errorf("sleep: got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,98 @@
+// Package config sets the flags of a command from, by decreasing
+// precedence, the command line, the environment variables, and a YAML or
+// TOML configuration file, the defaults of the flags coming last.
+//
+// The settings are named after the flags in all of the sources: the
+// -database.pool.max-open flag is set by the TITANIC_DATABASE_POOL_MAX_OPEN
+// environment variable, and by the max-open key of the [database.pool] table
+// of a TOML file, or of the pool map of the database map of a YAML file.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix prefixes the names of the environment variables.
+const EnvPrefix = "TITANIC_"
+
+// FileFlag is the flag naming the configuration file, if any; it is set
+// from the command line, or the environment.
+const FileFlag = "config"
+
+// EnvName returns the name of the environment variable setting the flag.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(flag))
+}
+
+// Parse parses the command line arguments of fs, and then sets the flags
+// left unset from the environment variables and then from the configuration
+// file named by the FileFlag flag of fs, if any. It fails on the invalid
+// values, and on the keys of the file which are flags of neither fs nor the
+// others, i.e. the other commands sharing the file.
+func Parse(fs *flag.FlagSet, args []string, others ...*flag.FlagSet) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] {
+			return
+		}
+		if v, ok := os.LookupEnv(EnvName(f.Name)); ok {
+			if e := fs.Set(f.Name, v); e != nil {
+				err = fmt.Errorf("%s: %v", EnvName(f.Name), e)
+			}
+			set[f.Name] = true
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	f := fs.Lookup(FileFlag)
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+	path := f.Value.String()
+	values, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == FileFlag || !isFlag(k, fs, others) {
+			return fmt.Errorf("%s: unknown setting %q", path, k)
+		}
+		if set[k] || fs.Lookup(k) == nil {
+			continue
+		}
+		if err := fs.Set(k, values[k]); err != nil {
+			return fmt.Errorf("%s: %s: %v", path, k, err)
+		}
+	}
+	return nil
+}
+
+func isFlag(name string, fs *flag.FlagSet, others []*flag.FlagSet) bool {
+	if fs.Lookup(name) != nil {
+		return true
+	}
+	for _, other := range others {
+		if other.Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadFile reads the settings of a configuration file, in TOML if its
+// extension is .toml, or else in YAML, keyed by the dotted path of each
+// value, e.g. "database.dsn".
+//
+// The settings are tables, or maps, of scalar values: the lists and the
+// arrays are rejected, as are the keys set twice, including by a dotted key
+// and its nested form.
+func ReadFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		_, err = toml.Decode(string(data), &tree)
+	} else {
+		err = yaml.Unmarshal(data, &tree)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	values := map[string]string{}
+	if err := flatten(values, "", tree); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return values, nil
+}
+
+// flatten adds the values of the tree to values, keyed by their dotted path
+// prefixed by prefix.
+func flatten(values map[string]string, prefix string, tree map[string]interface{}) error {
+	keys := make([]string, 0, len(tree))
+	for k := range tree {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key := prefix + k
+		if v, ok := tree[k].(map[string]interface{}); ok {
+			if err := flatten(values, key+".", v); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := formatValue(tree[k])
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		if _, ok := values[key]; ok {
+			return fmt.Errorf("duplicate key %q", key)
+		}
+		values[key] = value
+	}
+	return nil
+}
+
+// formatValue formats the scalar value as the flags parse it.
+func formatValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case nil:
+		return "", fmt.Errorf("missing value")
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
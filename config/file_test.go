@@ -0,0 +1,167 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes the configuration file in a directory of its own, along
+// with the function removing it.
+func writeFile(t *testing.T, name, data string) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestReadFile(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		file string
+		data string
+		want map[string]string
+	}{
+		{
+			name: "yaml nested keys",
+			file: "titanic.yaml",
+			data: "database:\n  type: sqlite\n  pool:\n    max-open: 20\n    max-lifetime: 30m\nhttp:\n  addr: \":3000\"\n",
+			want: map[string]string{"database.type": "sqlite", "database.pool.max-open": "20", "database.pool.max-lifetime": "30m", "http.addr": ":3000"},
+		},
+		{
+			name: "yaml dotted keys",
+			file: "titanic.yml",
+			data: "database.type: sqlite\nratelimit.rate: 2.5\n",
+			want: map[string]string{"database.type": "sqlite", "ratelimit.rate": "2.5"},
+		},
+		{
+			name: "yaml comments",
+			file: "titanic.yaml",
+			data: "# the database\ndatabase: # nested\n  dsn: postgresql://host/db#fragment # trailing\n  path: '# not a comment'\n",
+			want: map[string]string{"database.dsn": "postgresql://host/db#fragment", "database.path": "# not a comment"},
+		},
+		{
+			name: "yaml quoting",
+			file: "titanic.yaml",
+			data: "a: \"tab\\tand \\\"quotes\\\"\"\nb: 'it''s'\nc: \"\"\nd: 'true'\ne: yes\n",
+			want: map[string]string{"a": "tab\tand \"quotes\"", "b": "it's", "c": "", "d": "true", "e": "yes"},
+		},
+		{
+			name: "toml tables",
+			file: "titanic.toml",
+			data: "[database]\ntype = \"sqlite\"\n\n[database.pool]\nmax-open = 20\nmax-lifetime = \"30m\"\n\n[ratelimit]\nrate = 2.5\nburst = 1_000\n",
+			want: map[string]string{"database.type": "sqlite", "database.pool.max-open": "20", "database.pool.max-lifetime": "30m", "ratelimit.rate": "2.5", "ratelimit.burst": "1000"},
+		},
+		{
+			name: "toml comments",
+			file: "titanic.toml",
+			data: "# the database\n[database] # table\ndsn = \"postgresql://host/db#fragment\" # trailing\n",
+			want: map[string]string{"database.dsn": "postgresql://host/db#fragment"},
+		},
+		{
+			name: "toml quoting",
+			file: "TITANIC.TOML",
+			data: "a = \"tab\\tand \\\"quotes\\\" \\u00e9\"\nb = 'C:\\path'\nc = \"\"\nd = true\n",
+			want: map[string]string{"a": "tab\tand \"quotes\" é", "b": `C:\path`, "c": "", "d": "true"},
+		},
+		{
+			name: "empty",
+			file: "titanic.yaml",
+			data: "# nothing\n",
+			want: map[string]string{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path, remove := writeFile(t, tc.file, tc.data)
+			defer remove()
+			got, err := ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ReadFile: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadFileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		file string
+		data string
+		want string
+	}{
+		{"yaml duplicate key", "titanic.yaml", "database:\n  type: sqlite\n  type: inmemory\n", "already defined"},
+		{"yaml duplicate dotted key", "titanic.yaml", "database.type: sqlite\ndatabase:\n  type: inmemory\n", `duplicate key "database.type"`},
+		{"yaml list", "titanic.yaml", "http:\n  addr:\n    - \":3000\"\n", "http.addr: unsupported value"},
+		{"yaml missing value", "titanic.yaml", "http:\n  addr:\n", "http.addr: missing value"},
+		{"yaml not a map", "titanic.yaml", "- a\n- b\n", "cannot unmarshal"},
+		{"toml duplicate key", "titanic.toml", "[database]\ntype = \"sqlite\"\ntype = \"inmemory\"\n", "database.type"},
+		{"toml duplicate table", "titanic.toml", "[database]\ntype = \"sqlite\"\n[database]\npath = \"t.db\"\n", "database"},
+		{"toml array", "titanic.toml", "[http]\naddr = [\":3000\"]\n", "http.addr: unsupported value"},
+		{"toml unterminated string", "titanic.toml", "a = \"open\n", "a"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path, remove := writeFile(t, tc.file, tc.data)
+			defer remove()
+			_, err := ReadFile(path)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("ReadFile: got %v, want an error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	path, remove := writeFile(t, "titanic.yaml", "http:\n  addr: \":3001\"\ngrpc:\n  addr: \":8083\"\ndatabase:\n  type: sqlite\nfile: data.csv\n")
+	defer remove()
+
+	newFlags := func() (*flag.FlagSet, map[string]*string) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		values := map[string]*string{
+			FileFlag:        fs.String(FileFlag, "", ""),
+			"http.addr":     fs.String("http.addr", ":3000", ""),
+			"grpc.addr":     fs.String("grpc.addr", ":8082", ""),
+			"database.type": fs.String("database.type", "inmemory", ""),
+		}
+		return fs, values
+	}
+	other := flag.NewFlagSet("import", flag.ContinueOnError)
+	other.String("file", "", "")
+
+	os.Setenv(EnvName("grpc.addr"), ":8084")
+	defer os.Unsetenv(EnvName("grpc.addr"))
+
+	fs, values := newFlags()
+	if err := Parse(fs, []string{"-config", path, "-database.type", "cockroachdb"}, other); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for name, want := range map[string]string{
+		"http.addr":     ":3001",       // the file over the default
+		"grpc.addr":     ":8084",       // the environment over the file
+		"database.type": "cockroachdb", // the command line over the file
+	} {
+		if got := *values[name]; got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+
+	// The keys of the other commands are unknown without them.
+	fs, _ = newFlags()
+	err := Parse(fs, []string{"-config", path})
+	if err == nil || !strings.Contains(err.Error(), `unknown setting "file"`) {
+		t.Errorf("Parse: got %v, want an unknown setting error", err)
+	}
+}
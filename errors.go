@@ -0,0 +1,66 @@
+package titanic
+
+import "errors"
+
+// Code identifies a kind of error, for the clients to tell errors apart
+// without parsing their messages. Codes are stable across releases.
+type Code string
+
+// Codes of the errors of the service. Other packages define the codes of
+// their own errors, e.g. ratelimit.CodeRateLimited.
+const (
+	CodeInternal         Code = "internal"
+	CodeNotFound         Code = "not_found"
+	CodeAlreadyExists    Code = "already_exists"
+	CodeInconsistentIDs  Code = "inconsistent_ids"
+	CodeInvalidQuery     Code = "invalid_query"
+	CodeMalformedRequest Code = "malformed_request"
+	CodeValidationFailed Code = "validation_failed"
+	CodeVersionConflict  Code = "version_conflict"
+	CodeUnauthenticated  Code = "unauthenticated"
+	CodeForbidden        Code = "forbidden"
+	CodeRepositoryWrite  Code = "repository_write_failed"
+	CodeRepositoryRead   Code = "repository_read_failed"
+	CodeCanceled         Code = "canceled"
+	CodeTimeout          Code = "timeout"
+)
+
+// Error is an error carrying a code. Its title summarizes the kind of
+// error, and is the same for all the errors of a code, while its detail, if
+// any, explains this occurrence of the error.
+type Error struct {
+	Code   Code
+	Title  string
+	Detail string
+}
+
+// NewError returns an error of the given code and title.
+func NewError(code Code, title string) *Error {
+	return &Error{Code: code, Title: title}
+}
+
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return e.Title
+	}
+	return e.Title + ": " + e.Detail
+}
+
+// WithDetail returns a copy of the error explained by the given detail.
+func (e *Error) WithDetail(detail string) *Error {
+	return &Error{Code: e.Code, Title: e.Title, Detail: detail}
+}
+
+// ErrorCode returns the code of err: the code of the *Error it is or wraps,
+// CodeValidationFailed for a *ValidationError, or else CodeInternal.
+func ErrorCode(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return CodeValidationFailed
+	}
+	return CodeInternal
+}
@@ -0,0 +1,29 @@
+package titanic
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the events published on the changes to the passengers.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+	// EventReset tells a resuming subscriber that some of the events
+	// following the last one it received are no longer retained: it must
+	// read the passengers again, as it missed changes. It carries no
+	// passenger, and is numbered after the events missed.
+	EventReset = "reset"
+)
+
+// Event notifies a change to a passenger. Events are numbered in the order
+// they are published, so that a subscriber can resume after the last event
+// it received.
+type Event struct {
+	ID       uint64    `json:"id"`
+	Type     string    `json:"type"`
+	PeopleID uuid.UUID `json:"people_id"`
+	At       time.Time `json:"at"`
+}
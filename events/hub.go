@@ -0,0 +1,149 @@
+// Package events provides the in-process publish/subscribe hub notifying the
+// subscribers of the changes to the passengers, as they are made.
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gitlab.com/hyperd/titanic"
+)
+
+// Default sizes of the hub buffers.
+const (
+	DefaultBufferSize  = 64
+	DefaultHistorySize = 1024
+)
+
+// ErrOverflow is returned to the subscribers which fell too far behind the
+// published events: they must subscribe again, resuming after the last event
+// they received.
+var ErrOverflow = errors.New("event subscriber overflow")
+
+// Hub publishes the events to all of its subscribers. Publishing never
+// blocks: each subscriber has its own buffer, and is dropped when the buffer
+// is full. The latest events are retained, so that subscribers can resume
+// after a disconnection.
+type Hub struct {
+	bufferSize  int
+	historySize int
+
+	mtx     sync.Mutex
+	lastID  uint64
+	history []titanic.Event // oldest first
+	subs    map[*Subscription]struct{}
+}
+
+// NewHub returns a hub buffering up to bufferSize events per subscriber, and
+// retaining the last historySize events.
+func NewHub(bufferSize, historySize int) *Hub {
+	return &Hub{
+		bufferSize:  bufferSize,
+		historySize: historySize,
+		subs:        map[*Subscription]struct{}{},
+	}
+}
+
+// Publish numbers the event, and sends it to the subscribers.
+func (h *Hub) Publish(e titanic.Event) titanic.Event {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	h.lastID++
+	e.ID = h.lastID
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+
+	if h.historySize > 0 {
+		if len(h.history) == h.historySize {
+			copy(h.history, h.history[1:])
+			h.history = h.history[:len(h.history)-1]
+		}
+		h.history = append(h.history, e)
+	}
+
+	for s := range h.subs {
+		select {
+		case s.ch <- e:
+		default:
+			delete(h.subs, s)
+			close(s.ch)
+		}
+	}
+	return e
+}
+
+// Subscribe returns a subscription to the events published after the one
+// numbered lastID, starting with those retained by the hub; a zero lastID
+// subscribes to the events to come only. When events following lastID are
+// no longer retained, or lastID is ahead of the hub, e.g. received before
+// the process restarted, the subscription starts with an EventReset,
+// followed by the retained events. The subscription must be closed once
+// done with.
+func (h *Hub) Subscribe(lastID uint64) *Subscription {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	s := &Subscription{
+		hub: h,
+		ch:  make(chan titanic.Event, h.bufferSize),
+	}
+	if lastID > 0 {
+		// The events up to first are missing from the history.
+		first := h.lastID
+		if len(h.history) > 0 {
+			first = h.history[0].ID - 1
+		}
+		if lastID < first || lastID > h.lastID {
+			s.backlog = append(s.backlog, titanic.Event{ID: first, Type: titanic.EventReset, At: time.Now()})
+			lastID = first
+		}
+		for _, e := range h.history {
+			if e.ID > lastID {
+				s.backlog = append(s.backlog, e)
+			}
+		}
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// Subscription receives the events published by a hub.
+type Subscription struct {
+	hub     *Hub
+	backlog []titanic.Event
+	ch      chan titanic.Event
+}
+
+// Next returns the next event, waiting for it to be published. It fails
+// with ErrOverflow when the subscriber fell behind, or with the error of ctx
+// once done.
+func (s *Subscription) Next(ctx context.Context) (titanic.Event, error) {
+	if len(s.backlog) > 0 {
+		e := s.backlog[0]
+		s.backlog = s.backlog[1:]
+		return e, nil
+	}
+	select {
+	case e, ok := <-s.ch:
+		if !ok {
+			return e, ErrOverflow
+		}
+		return e, nil
+	case <-ctx.Done():
+		return titanic.Event{}, ctx.Err()
+	}
+}
+
+// Close unsubscribes from the hub.
+func (s *Subscription) Close() {
+	s.hub.mtx.Lock()
+	defer s.hub.mtx.Unlock()
+	if _, ok := s.hub.subs[s]; ok {
+		delete(s.hub.subs, s)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"gitlab.com/hyperd/titanic"
+)
+
+// backlog returns the ids and the types of the events the subscription
+// starts with.
+func backlog(s *Subscription) []string {
+	var events []string
+	for _, e := range s.backlog {
+		events = append(events, fmt.Sprintf("%s:%d", e.Type, e.ID))
+	}
+	return events
+}
+
+func TestSubscribeResume(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		historySize int
+		lastID      uint64
+		want        []string
+	}{
+		{"events to come", 2, 0, nil},
+		{"retained", 2, 3, []string{"created:4", "created:5"}},
+		{"up to date", 2, 5, nil},
+		{"missed events", 2, 2, []string{"reset:3", "created:4", "created:5"}},
+		{"ahead of the hub", 2, 9, []string{"reset:3", "created:4", "created:5"}},
+		{"no history", 0, 3, []string{"reset:5"}},
+		{"no history, up to date", 0, 5, nil},
+	} {
+		h := NewHub(DefaultBufferSize, tc.historySize)
+		for i := 0; i < 5; i++ {
+			h.Publish(titanic.Event{Type: titanic.EventCreated, PeopleID: uuid.New()})
+		}
+		s := h.Subscribe(tc.lastID)
+		if got := backlog(s); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Subscribe(%d): got %v, want %v", tc.name, tc.lastID, got, tc.want)
+		}
+		s.Close()
+	}
+}
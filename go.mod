@@ -3,14 +3,13 @@ module gitlab.com/hyperd/titanic
 go 1.13
 
 require (
-	github.com/asaskevich/govalidator v0.0.0-20190424111038-f61b66f89f4a // indirect
+	github.com/BurntSushi/toml v0.3.1
+	github.com/VividCortex/gohistogram v1.0.0 // indirect
 	github.com/go-kit/kit v0.9.0
 	github.com/google/uuid v1.1.1
 	github.com/jinzhu/gorm v1.9.11
-	github.com/qor/validations v0.0.0-20171228122639-f364bca61b46 // indirect
-	gitlab.com/hyperd/titanic/implementation v0.0.0-20191121205005-9dc5dfda259b // indirect
-	gitlab.com/hyperd/titanic/inmemory v0.0.0-20191121205005-9dc5dfda259b // indirect
-	gitlab.com/hyperd/titanic/transport v0.0.0-20191121205005-9dc5dfda259b // indirect
-	gitlab.com/hyperd/titanic/transport/http v0.0.0-20191121205005-9dc5dfda259b // indirect
-	golang.org/x/net v0.0.0-20191126235420-ef20fe5d7933 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/lib/pq v1.1.1
+	github.com/mattn/go-sqlite3 v1.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
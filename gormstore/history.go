@@ -0,0 +1,129 @@
+package gormstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/audit"
+)
+
+// ChangeRow is a titanic.Change as stored in the people_audit table, with
+// the snapshots of the passenger encoded as JSON.
+type ChangeRow struct {
+	ID        uuid.UUID `gorm:"primary_key"`
+	PeopleID  uuid.UUID `gorm:"index"`
+	Action    string
+	Actor     string
+	RequestID string
+	At        time.Time
+	Before    *string
+	After     *string
+}
+
+func (ChangeRow) TableName() string { return "people_audit" }
+
+// RecordChange records the change to the passenger in the audit table, as
+// part of the transaction making it. The time of the change is stored in
+// UTC, so that it sorts as the strings SQLite stores the times as.
+func RecordChange(ctx context.Context, tx *gorm.DB, action string, id uuid.UUID, before, after *titanic.People) error {
+	c := audit.NewChange(ctx, action, id, before, after)
+	row := ChangeRow{
+		ID:        c.ID,
+		PeopleID:  c.PeopleID,
+		Action:    c.Action,
+		Actor:     c.Actor,
+		RequestID: c.RequestID,
+		At:        c.At.UTC(),
+	}
+
+	var err error
+	if row.Before, err = encodeSnapshot(before); err != nil {
+		return err
+	}
+	if row.After, err = encodeSnapshot(after); err != nil {
+		return err
+	}
+	return tx.Create(&row).Error
+}
+
+// RecordUpdate records the change to the passenger, given its snapshot
+// before the change, taking the snapshot after it.
+func RecordUpdate(ctx context.Context, tx *gorm.DB, action string, id uuid.UUID, before *titanic.People) error {
+	after, err := Snapshot(tx, id)
+	if err != nil {
+		return err
+	}
+	return RecordChange(ctx, tx, action, id, before, after)
+}
+
+// Snapshot returns the passenger as currently stored, deleted or not, or
+// nil when missing.
+func Snapshot(tx *gorm.DB, id uuid.UUID) (*titanic.People, error) {
+	var people titanic.People
+	if err := tx.Unscoped().Where("id = ?", id).First(&people).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &people, nil
+}
+
+// History returns the changes made to the passenger, oldest first, or fails
+// with titanic.ErrNotFound if there are none.
+func History(tx *gorm.DB, id uuid.UUID) ([]titanic.Change, error) {
+	var rows []ChangeRow
+	if err := tx.Where("people_id = ?", id).Order("at ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, titanic.ErrNotFound
+	}
+
+	var err error
+	history := make([]titanic.Change, len(rows))
+	for i, row := range rows {
+		history[i] = titanic.Change{
+			ID:        row.ID,
+			PeopleID:  row.PeopleID,
+			Action:    row.Action,
+			Actor:     row.Actor,
+			RequestID: row.RequestID,
+			At:        row.At,
+		}
+		if history[i].Before, err = decodeSnapshot(row.Before); err != nil {
+			return nil, err
+		}
+		if history[i].After, err = decodeSnapshot(row.After); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+func encodeSnapshot(p *titanic.People) (*string, error) {
+	if p == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	s := string(b)
+	return &s, nil
+}
+
+func decodeSnapshot(s *string) (*titanic.People, error) {
+	if s == nil {
+		return nil, nil
+	}
+	var p titanic.People
+	if err := json.Unmarshal([]byte(*s), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
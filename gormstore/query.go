@@ -0,0 +1,97 @@
+// Package gormstore holds the GORM queries shared by the SQL repositories:
+// the filters, the order and the keyset pagination of the listings of the
+// passengers, the history of their changes and their statistics.
+package gormstore
+
+import (
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"gitlab.com/hyperd/titanic"
+)
+
+// SortPeople orders the rows by the sort keys, then by ID.
+func SortPeople(db *gorm.DB, keys []titanic.SortKey) *gorm.DB {
+	for _, k := range keys {
+		if k.Desc {
+			db = db.Order(k.Column() + " DESC")
+		} else {
+			db = db.Order(k.Column() + " ASC")
+		}
+	}
+	return db.Order("id ASC")
+}
+
+// FilterPeople translates the filter into WHERE clauses.
+func FilterPeople(db *gorm.DB, f titanic.PeopleFilter) *gorm.DB {
+	if f.Survived != nil {
+		db = db.Where("survived = ?", *f.Survived)
+	}
+	if f.Pclass != nil {
+		db = db.Where("pclass = ?", *f.Pclass)
+	}
+	if f.Sex != "" {
+		db = db.Where("sex = ?", f.Sex)
+	}
+	if f.AgeMin != nil {
+		db = db.Where("age >= ?", *f.AgeMin)
+	}
+	if f.AgeMax != nil {
+		db = db.Where("age <= ?", *f.AgeMax)
+	}
+	if f.FareMin != nil {
+		db = db.Where("fare >= ?", *f.FareMin)
+	}
+	if f.FareMax != nil {
+		db = db.Where("fare <= ?", *f.FareMax)
+	}
+	return db
+}
+
+// AfterCursor selects the rows sorting after the cursor, in keyset fashion:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... OR (k1 = v1 AND ... AND id > ID).
+// NULLs sort first in ascending order and last in descending order.
+func AfterCursor(db *gorm.DB, c titanic.Cursor, keys []titanic.SortKey) *gorm.DB {
+	var (
+		clauses []string
+		args    []interface{}
+		eq      []string
+		eqArgs  []interface{}
+	)
+
+	for i, k := range keys {
+		col, v := k.Column(), c.Values[i]
+
+		var after string
+		var afterArgs []interface{}
+		switch {
+		case v == nil && !k.Desc:
+			after = col + " IS NOT NULL"
+		case v == nil && k.Desc:
+			// Nothing sorts after NULL in descending order.
+		case !k.Desc:
+			after, afterArgs = col+" > ?", []interface{}{v}
+		default:
+			after, afterArgs = "("+col+" < ? OR "+col+" IS NULL)", []interface{}{v}
+		}
+
+		if after != "" {
+			terms := append(append([]string{}, eq...), after)
+			clauses = append(clauses, "("+strings.Join(terms, " AND ")+")")
+			args = append(append(args, eqArgs...), afterArgs...)
+		}
+
+		if v == nil {
+			eq = append(eq, col+" IS NULL")
+		} else {
+			eq = append(eq, col+" = ?")
+			eqArgs = append(eqArgs, v)
+		}
+	}
+
+	terms := append(append([]string{}, eq...), "id > ?")
+	clauses = append(clauses, "("+strings.Join(terms, " AND ")+")")
+	args = append(append(args, eqArgs...), c.ID)
+
+	return db.Where(strings.Join(clauses, " OR "), args...)
+}
@@ -0,0 +1,210 @@
+package gormstore
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"gitlab.com/hyperd/titanic"
+)
+
+// Statistics computes the statistics of the passengers. The aggregates and
+// the medians must be read from the same snapshot, e.g. in a transaction.
+func Statistics(db *gorm.DB, q titanic.StatisticsQuery) (titanic.Statistics, error) {
+	stats := titanic.Statistics{GroupBy: q.GroupBy, Groups: []titanic.StatisticsGroup{}}
+	groups := statisticsGroups(q)
+
+	columns := append(aliasGroups(groups),
+		"COUNT(*)",
+		"COUNT(survived)",
+		"COUNT(CASE WHEN survived THEN 1 END)",
+		"AVG(age)",
+		"AVG(fare)",
+	)
+
+	rows, err := FilterPeople(db.Model(&titanic.People{}), q.Filter).
+		Select(strings.Join(columns, ", ")).
+		Group(strings.Join(groups, ", ")).
+		Rows()
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	var (
+		indexes = map[string]int{}
+		known   []int64
+	)
+	for rows.Next() {
+		var (
+			count, k, survived int64
+			meanAge, meanFare  sql.NullFloat64
+		)
+		dest := append(groupDest(q), &count, &k, &survived, &meanAge, &meanFare)
+		if err := rows.Scan(dest...); err != nil {
+			return stats, err
+		}
+		// Aggregating no rows without grouping still yields one row.
+		if count == 0 {
+			continue
+		}
+
+		g := scanGroup(q, dest)
+		g.Count, g.Survived = int(count), int(survived)
+		g.MeanAge, g.MeanFare = nullFloat(meanAge), nullFloat(meanFare)
+
+		indexes[g.Key()] = len(stats.Groups)
+		stats.Groups = append(stats.Groups, g)
+		known = append(known, k)
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	for i, k := range known {
+		if k > 0 {
+			rate := float64(stats.Groups[i].Survived) / float64(k)
+			stats.Groups[i].SurvivalRate = &rate
+		}
+	}
+
+	medianAges, err := medians(db, q, groups, "age")
+	if err != nil {
+		return stats, err
+	}
+	medianFares, err := medians(db, q, groups, "fare")
+	if err != nil {
+		return stats, err
+	}
+	for key, i := range indexes {
+		stats.Groups[i].MedianAge = medianAges[key]
+		stats.Groups[i].MedianFare = medianFares[key]
+	}
+
+	titanic.SortGroups(stats.Groups)
+
+	return stats, nil
+}
+
+// medians computes the median of the column for each group, keyed by group.
+// The rows of each group are ranked by the column, and the median is the
+// average of the middle row, or of the middle two for an even count.
+func medians(db *gorm.DB, q titanic.StatisticsQuery, groups []string, column string) (map[string]*float64, error) {
+	var partition string
+	if len(groups) > 0 {
+		partition = "PARTITION BY " + strings.Join(groups, ", ")
+	}
+
+	ranked := FilterPeople(db.Model(&titanic.People{}), q.Filter).
+		Where(column + " IS NOT NULL").
+		Select(strings.Join(append(aliasGroups(groups),
+			column+" AS v",
+			"ROW_NUMBER() OVER ("+strings.TrimSpace(partition+" ORDER BY "+column)+") AS rn",
+			"COUNT(*) OVER ("+partition+") AS cnt",
+		), ", ")).
+		QueryExpr()
+
+	aliases := make([]string, len(groups))
+	for i := range groups {
+		aliases[i] = fmt.Sprintf("g%d", i)
+	}
+	selected := strings.Join(append(aliases, "AVG(v)"), ", ")
+
+	query := "SELECT " + selected + " FROM (?) AS ranked WHERE rn * 2 BETWEEN cnt AND cnt + 2"
+	if len(aliases) > 0 {
+		query += " GROUP BY " + strings.Join(aliases, ", ")
+	}
+
+	rows, err := db.Raw(query, ranked).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	medians := map[string]*float64{}
+	for rows.Next() {
+		var median sql.NullFloat64
+		dest := append(groupDest(q), &median)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		medians[scanGroup(q, dest).Key()] = nullFloat(median)
+	}
+
+	return medians, rows.Err()
+}
+
+// statisticsGroups returns the expressions grouping the rows, in the order
+// of the StatisticsGroup fields.
+func statisticsGroups(q titanic.StatisticsQuery) []string {
+	var groups []string
+	if q.Grouped(titanic.GroupByPclass) {
+		groups = append(groups, "pclass")
+	}
+	if q.Grouped(titanic.GroupBySex) {
+		groups = append(groups, "sex")
+	}
+	if q.Grouped(titanic.GroupByAgeBand) {
+		// The width is a validated integer, never user provided text.
+		groups = append(groups, fmt.Sprintf("age - age %% %d", q.AgeBandWidth))
+	}
+	return groups
+}
+
+func aliasGroups(groups []string) []string {
+	aliased := make([]string, len(groups))
+	for i, g := range groups {
+		aliased[i] = fmt.Sprintf("%s AS g%d", g, i)
+	}
+	return aliased
+}
+
+// groupDest returns the scan destinations of the group columns.
+func groupDest(q titanic.StatisticsQuery) []interface{} {
+	var dest []interface{}
+	if q.Grouped(titanic.GroupByPclass) {
+		dest = append(dest, &sql.NullInt64{})
+	}
+	if q.Grouped(titanic.GroupBySex) {
+		dest = append(dest, &sql.NullString{})
+	}
+	if q.Grouped(titanic.GroupByAgeBand) {
+		dest = append(dest, &sql.NullInt64{})
+	}
+	return dest
+}
+
+// scanGroup builds the group out of the scanned group columns.
+func scanGroup(q titanic.StatisticsQuery, dest []interface{}) titanic.StatisticsGroup {
+	var g titanic.StatisticsGroup
+	i := 0
+	if q.Grouped(titanic.GroupByPclass) {
+		if v := dest[i].(*sql.NullInt64); v.Valid {
+			pclass := int(v.Int64)
+			g.Pclass = &pclass
+		}
+		i++
+	}
+	if q.Grouped(titanic.GroupBySex) {
+		if v := dest[i].(*sql.NullString); v.Valid {
+			sex := v.String
+			g.Sex = &sex
+		}
+		i++
+	}
+	if q.Grouped(titanic.GroupByAgeBand) {
+		if v := dest[i].(*sql.NullInt64); v.Valid {
+			band := titanic.NewAgeBand(int(v.Int64), q.AgeBandWidth)
+			g.AgeBand = &band
+		}
+	}
+	return g
+}
+
+func nullFloat(f sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
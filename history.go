@@ -0,0 +1,30 @@
+package titanic
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions of the changes recorded in the history of the passengers.
+const (
+	ActionCreate  = "create"
+	ActionPut     = "put"
+	ActionPatch   = "patch"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// Change records a single change to a passenger: the passenger before and
+// after it, who made it, and as part of which request. Before is nil for
+// creations.
+type Change struct {
+	ID        uuid.UUID `json:"id"`
+	PeopleID  uuid.UUID `json:"people_id"`
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	At        time.Time `json:"at"`
+	Before    *People   `json:"before,omitempty"`
+	After     *People   `json:"after,omitempty"`
+}
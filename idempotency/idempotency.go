@@ -0,0 +1,75 @@
+// Package idempotency keeps the responses to the requests carrying an
+// idempotency key, so that the retries of a request are answered with the
+// response to the first one, instead of being executed again.
+package idempotency
+
+import (
+	"context"
+
+	"gitlab.com/hyperd/titanic"
+)
+
+// Codes of the idempotency errors.
+const (
+	CodeInvalidKey titanic.Code = "invalid_idempotency_key"
+	CodeInFlight   titanic.Code = "idempotency_key_in_flight"
+	CodeMismatch   titanic.Code = "idempotency_key_mismatch"
+)
+
+var (
+	// ErrInvalidKey is returned when an idempotency key is too long.
+	ErrInvalidKey = titanic.NewError(CodeInvalidKey, "invalid idempotency key")
+
+	// ErrInFlight is returned when a request is retried while the first
+	// one is still being executed.
+	ErrInFlight = titanic.NewError(CodeInFlight, "request with the same idempotency key in progress")
+
+	// ErrMismatch is returned when an idempotency key is reused for a
+	// different request.
+	ErrMismatch = titanic.NewError(CodeMismatch, "idempotency key reused for a different request")
+)
+
+// Response is a response kept by a Store.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Record is the record of an idempotency key: the fingerprint of the request
+// which reserved it, and its response, nil while the request is in flight.
+type Record struct {
+	Fingerprint string    `json:"fingerprint"`
+	Response    *Response `json:"response,omitempty"`
+}
+
+// Store keeps the records of the idempotency keys, for a TTL of its own.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Reserve reserves the key for the request of the given fingerprint,
+	// unless the key is already reserved, in which case it returns false
+	// along with the record of the key.
+	Reserve(key, fingerprint string) (Record, bool, error)
+	// Complete records the response to the request which reserved the key.
+	Complete(key string, resp Response) error
+	// Release releases the key reserved by a request whose response isn't
+	// kept, so that the request can be retried.
+	Release(key string) error
+}
+
+// MaxKeyLength is the maximum length of the idempotency keys.
+const MaxKeyLength = 255
+
+type keyKey struct{}
+
+// NewContext returns a copy of ctx carrying the idempotency key, to be sent
+// along with the request by the clients.
+func NewContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyKey{}, key)
+}
+
+// FromContext returns the idempotency key carried by ctx, or "".
+func FromContext(ctx context.Context) string {
+	key, _ := ctx.Value(keyKey{}).(string)
+	return key
+}
@@ -0,0 +1,71 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is the interval at which the expired records are dropped.
+const sweepInterval = time.Minute
+
+// NewInmemStore returns a Store keeping the records in memory, for the
+// given TTL since the key was reserved.
+func NewInmemStore(ttl time.Duration) Store {
+	return &inmemStore{
+		ttl:       ttl,
+		records:   map[string]inmemRecord{},
+		lastSweep: time.Now(),
+	}
+}
+
+type inmemStore struct {
+	ttl time.Duration
+
+	mtx       sync.Mutex
+	records   map[string]inmemRecord
+	lastSweep time.Time
+}
+
+type inmemRecord struct {
+	Record
+	expires time.Time
+}
+
+func (s *inmemStore) Reserve(key, fingerprint string) (Record, bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, r := range s.records {
+			if !now.Before(r.expires) {
+				delete(s.records, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if r, ok := s.records[key]; ok && now.Before(r.expires) {
+		return r.Record, false, nil
+	}
+	r := Record{Fingerprint: fingerprint}
+	s.records[key] = inmemRecord{Record: r, expires: now.Add(s.ttl)}
+	return r, true, nil
+}
+
+func (s *inmemStore) Complete(key string, resp Response) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if r, ok := s.records[key]; ok {
+		r.Response = &resp
+		s.records[key] = r
+	}
+	return nil
+}
+
+func (s *inmemStore) Release(key string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.records, key)
+	return nil
+}
@@ -8,24 +8,57 @@ import (
 	"github.com/go-kit/kit/log/level"
 	"github.com/google/uuid"
 	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/events"
 )
 
 // service implements the Titanic Service
 type service struct {
 	repository titanic.Repository
+	events     *events.Hub
 	logger     log.Logger
 }
 
-// NewService creates and returns a new Titanic service instance
+// NewService creates and returns a new Titanic service instance, notifying
+// its watchers of the changes made through it.
 func NewService(rep titanic.Repository, logger log.Logger) titanic.Service {
 	return &service{
 		repository: rep,
+		events:     events.NewHub(events.DefaultBufferSize, events.DefaultHistorySize),
 		logger:     logger,
 	}
 }
 
+// publish notifies the watchers of a change to the passenger.
+func (s *service) publish(typ string, id uuid.UUID) {
+	s.events.Publish(titanic.Event{Type: typ, PeopleID: id})
+}
+
+// publishCreated notifies the watchers of a passenger created by the
+// repository, which assigned its id.
+func (s *service) publishCreated(id string) {
+	if uuid, err := uuid.Parse(id); err == nil {
+		s.publish(titanic.EventCreated, uuid)
+	}
+}
+
+// repositoryError returns the errors of the repository the clients are told,
+// e.g. titanic.ErrNotFound, as they are, and replaces the others, e.g. the
+// failures of the database, with the given error.
+func repositoryError(err, otherwise error) error {
+	if err == sql.ErrNoRows {
+		return titanic.ErrNotFound
+	}
+	if titanic.ErrorCode(err) != titanic.CodeInternal {
+		return err
+	}
+	return otherwise
+}
+
 func (s *service) PostPeople(ctx context.Context, people titanic.People) (string, error) {
 	logger := log.With(s.logger, "method", "PostPeople")
+	if err := people.Validate(false); err != nil {
+		return "", err
+	}
 	uuid := uuid.New()
 
 	people.ID = uuid
@@ -34,64 +67,177 @@ func (s *service) PostPeople(ctx context.Context, people titanic.People) (string
 
 	if err != nil {
 		level.Error(logger).Log("err", err)
-		return id, err
+		return "", repositoryError(err, titanic.ErrCmdRepository)
 	}
+	s.publishCreated(id)
 	return id, err
 }
 
+func (s *service) PostPeopleBatch(ctx context.Context, people []titanic.People) ([]string, error) {
+	logger := log.With(s.logger, "method", "PostPeopleBatch")
+	if err := titanic.ValidatePeople(people); err != nil {
+		return nil, err
+	}
+
+	for i := range people {
+		people[i].ID = uuid.New()
+	}
+
+	ids, err := s.repository.PostPeopleBatch(ctx, people)
+
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, repositoryError(err, titanic.ErrCmdRepository)
+	}
+	for _, id := range ids {
+		s.publishCreated(id)
+	}
+	return ids, err
+}
+
 func (s *service) GetPeopleByID(ctx context.Context, uuid uuid.UUID) (titanic.People, error) {
 	logger := log.With(s.logger, "method", "GetPeopleByID")
 	people, err := s.repository.GetPeopleByID(ctx, uuid)
 	if err != nil {
 		level.Error(logger).Log("err", err)
-		if err == sql.ErrNoRows {
-			return people, titanic.ErrNotFound
-		}
-		return people, titanic.ErrQueryRepository
+		return people, repositoryError(err, titanic.ErrQueryRepository)
 	}
 	return people, err
 }
 
 func (s *service) PutPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) error {
 	logger := log.With(s.logger, "method", "PutPeople")
-	if err := s.repository.PutPeople(ctx, uuid, p); err != nil {
-		level.Error(logger).Log("err", err)
+	if err := p.Validate(false); err != nil {
 		return err
 	}
+	created, err := s.repository.PutPeople(ctx, uuid, p)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return repositoryError(err, titanic.ErrCmdRepository)
+	}
+	// PUT creates the passenger when missing, which the watchers are
+	// told apart from an update.
+	if created {
+		s.publish(titanic.EventCreated, uuid)
+	} else {
+		s.publish(titanic.EventUpdated, uuid)
+	}
 	return nil
 }
 
 func (s *service) PatchPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) error {
 	logger := log.With(s.logger, "method", "PatchPeople")
+	if err := p.Validate(true); err != nil {
+		return err
+	}
 	if err := s.repository.PatchPeople(ctx, uuid, p); err != nil {
 		level.Error(logger).Log("err", err)
-		return err
+		return repositoryError(err, titanic.ErrCmdRepository)
 	}
+	s.publish(titanic.EventUpdated, uuid)
 	return nil
 }
 
-func (s *service) DeletePeople(ctx context.Context, uuid uuid.UUID) (string, error) {
+func (s *service) DeletePeople(ctx context.Context, uuid uuid.UUID, version int) (string, error) {
 	logger := log.With(s.logger, "method", "DeletePeople")
-	id, err := s.repository.DeletePeople(ctx, uuid)
+	id, err := s.repository.DeletePeople(ctx, uuid, version)
 	if err != nil {
 		level.Error(logger).Log("err", err)
-		if err == sql.ErrNoRows {
-			return uuid.String(), titanic.ErrNotFound
-		}
-		return uuid.String(), titanic.ErrQueryRepository
+		return uuid.String(), repositoryError(err, titanic.ErrCmdRepository)
 	}
+	s.publish(titanic.EventDeleted, uuid)
 	return id, err
 }
 
-func (s *service) GetPeople(ctx context.Context) ([]titanic.People, error) {
+func (s *service) RestorePeople(ctx context.Context, uuid uuid.UUID) error {
+	logger := log.With(s.logger, "method", "RestorePeople")
+	if err := s.repository.RestorePeople(ctx, uuid); err != nil {
+		level.Error(logger).Log("err", err)
+		return repositoryError(err, titanic.ErrCmdRepository)
+	}
+	// The passenger is back in the listings.
+	s.publish(titanic.EventCreated, uuid)
+	return nil
+}
+
+func (s *service) GetPeopleHistory(ctx context.Context, uuid uuid.UUID) ([]titanic.Change, error) {
+	logger := log.With(s.logger, "method", "GetPeopleHistory")
+	history, err := s.repository.GetPeopleHistory(ctx, uuid)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return nil, repositoryError(err, titanic.ErrQueryRepository)
+	}
+	return history, nil
+}
+
+func (s *service) GetPeople(ctx context.Context, q titanic.PeopleQuery) (titanic.PeoplePage, error) {
 	logger := log.With(s.logger, "method", "GetPeople")
-	people, err := s.repository.GetPeople(ctx)
+	if err := q.Validate(); err != nil {
+		level.Error(logger).Log("err", err)
+		return titanic.PeoplePage{}, err
+	}
+	page, err := s.repository.GetPeople(ctx, q)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return page, repositoryError(err, titanic.ErrQueryRepository)
+	}
+	return page, err
+}
+
+func (s *service) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) error {
+	logger := log.With(s.logger, "method", "StreamPeople")
+	if err := q.Filter.Validate(); err != nil {
+		level.Error(logger).Log("err", err)
+		return err
+	}
+	// The errors of fn, e.g. the client going away, are returned as they
+	// are, and those of the repository as by the other methods.
+	var fnErr error
+	err := s.repository.StreamPeople(ctx, q, func(p titanic.People) error {
+		fnErr = fn(p)
+		return fnErr
+	})
 	if err != nil {
 		level.Error(logger).Log("err", err)
-		if err == sql.ErrNoRows {
-			return nil, titanic.ErrNotFound
+		if err == fnErr {
+			return err
 		}
-		return nil, err
+		return repositoryError(err, titanic.ErrQueryRepository)
 	}
-	return people, err
+	return nil
+}
+
+func (s *service) WatchPeople(ctx context.Context, lastEventID uint64, fn func(titanic.Event) error) error {
+	logger := log.With(s.logger, "method", "WatchPeople")
+	sub := s.events.Subscribe(lastEventID)
+	defer sub.Close()
+	for {
+		e, err := sub.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				// The watcher went away.
+				return nil
+			}
+			level.Error(logger).Log("err", err)
+			return err
+		}
+		if err := fn(e); err != nil {
+			level.Error(logger).Log("err", err)
+			return err
+		}
+	}
+}
+
+func (s *service) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (titanic.Statistics, error) {
+	logger := log.With(s.logger, "method", "GetStatistics")
+	if err := q.Validate(); err != nil {
+		level.Error(logger).Log("err", err)
+		return titanic.Statistics{}, err
+	}
+	stats, err := s.repository.GetStatistics(ctx, q)
+	if err != nil {
+		level.Error(logger).Log("err", err)
+		return stats, repositoryError(err, titanic.ErrQueryRepository)
+	}
+	return stats, nil
 }
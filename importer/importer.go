@@ -0,0 +1,232 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"gitlab.com/hyperd/titanic"
+)
+
+// DefaultBatchSize is the number of passengers written through the service
+// at once, when not configured otherwise.
+const DefaultBatchSize = 100
+
+// Header is the column layout of data/titanic.csv.
+var Header = []string{
+	"Survived",
+	"Pclass",
+	"Name",
+	"Sex",
+	"Age",
+	"Siblings/Spouses Aboard",
+	"Parents/Children Aboard",
+	"Fare",
+}
+
+// CodeHeader is the code of ErrHeader.
+const CodeHeader titanic.Code = "unexpected_csv_header"
+
+// ErrHeader is returned when the CSV header doesn't match Header.
+var ErrHeader = titanic.NewError(CodeHeader, "unexpected CSV header")
+
+// LineError reports a CSV line that could not be imported.
+type LineError struct {
+	Line int    `json:"line"`
+	Err  string `json:"error"`
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Report summarises an import.
+type Report struct {
+	Imported int         `json:"imported"`
+	Failed   int         `json:"failed"`
+	Errors   []LineError `json:"errors,omitempty"`
+}
+
+// Importer reads passengers from CSV and writes them through the titanic
+// service in batches.
+type Importer struct {
+	svc       titanic.Service
+	batchSize int
+	logger    log.Logger
+}
+
+// New returns an Importer writing through the given service. A batchSize
+// lower than 1 falls back to DefaultBatchSize.
+func New(svc titanic.Service, batchSize int, logger log.Logger) *Importer {
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+	return &Importer{
+		svc:       svc,
+		batchSize: batchSize,
+		logger:    log.With(logger, "component", "importer"),
+	}
+}
+
+// Import reads the passengers from r, laid out as described by Header.
+// Invalid lines are skipped and reported, while the valid ones are imported.
+// An error is only returned when the input can't be read at all.
+func (i *Importer) Import(ctx context.Context, r io.Reader) (Report, error) {
+	var report Report
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(Header)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if _, ok := err.(*csv.ParseError); ok || err == io.EOF {
+			return report, ErrHeader
+		}
+		return report, err
+	}
+	if !validHeader(header) {
+		return report, ErrHeader
+	}
+
+	var (
+		batch []titanic.People
+		lines []int
+	)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if _, err := i.svc.PostPeopleBatch(ctx, batch); err != nil {
+			level.Error(i.logger).Log("lines", fmt.Sprintf("%d-%d", lines[0], lines[len(lines)-1]), "err", err)
+			for _, line := range lines {
+				report.fail(line, err)
+			}
+		} else {
+			report.Imported += len(batch)
+		}
+		batch, lines = batch[:0], lines[:0]
+	}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return report, err
+			}
+			report.fail(line, err)
+			continue
+		}
+
+		p, err := ParseRecord(record)
+		if err != nil {
+			report.fail(line, err)
+			continue
+		}
+
+		batch = append(batch, p)
+		lines = append(lines, line)
+		if len(batch) == i.batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	return report, nil
+}
+
+func (r *Report) fail(line int, err error) {
+	r.Failed++
+	r.Errors = append(r.Errors, LineError{Line: line, Err: err.Error()})
+}
+
+func validHeader(header []string) bool {
+	for i, h := range header {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		if strings.TrimSpace(h) != Header[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseRecord parses and validates a single CSV record, laid out as described
+// by Header. Fractional ages, used for infants in data/titanic.csv, are
+// truncated to the completed years.
+func ParseRecord(record []string) (titanic.People, error) {
+	var p titanic.People
+
+	if len(record) != len(Header) {
+		return p, fmt.Errorf("expected %d fields, got %d", len(Header), len(record))
+	}
+	for i := range record {
+		record[i] = strings.TrimSpace(record[i])
+	}
+
+	survived, err := strconv.ParseBool(record[0])
+	if err != nil {
+		return p, fieldError(0, "must be 0 or 1")
+	}
+	p.Survived = &survived
+
+	pclass, err := strconv.Atoi(record[1])
+	if err != nil || pclass < 1 || pclass > 3 {
+		return p, fieldError(1, "must be 1, 2 or 3")
+	}
+	p.Pclass = &pclass
+
+	if p.Name = record[2]; p.Name == "" {
+		return p, fieldError(2, "must not be empty")
+	}
+
+	switch p.Sex = record[3]; p.Sex {
+	case "male", "female", "not declared":
+	default:
+		return p, fieldError(3, "must be male, female or not declared")
+	}
+
+	age, err := strconv.ParseFloat(record[4], 64)
+	if err != nil || age < 0 || age > 116 {
+		return p, fieldError(4, "must be a number between 0 and 116")
+	}
+	years := int(age)
+	p.Age = &years
+
+	siblingsSpouses, err := strconv.Atoi(record[5])
+	if err != nil || siblingsSpouses < 0 || siblingsSpouses > 20 {
+		return p, fieldError(5, "must be an integer between 0 and 20")
+	}
+	p.SiblingsSpousesAbroad = &siblingsSpouses
+
+	parentsChildren, err := strconv.Atoi(record[6])
+	if err != nil || parentsChildren < 0 || parentsChildren > 20 {
+		return p, fieldError(6, "must be an integer between 0 and 20")
+	}
+	p.ParentsChildrenAboard = &parentsChildren
+
+	fare, err := strconv.ParseFloat(record[7], 32)
+	if err != nil || fare < 0 {
+		return p, fieldError(7, "must be a non-negative number")
+	}
+	fare32 := float32(fare)
+	p.Fare = &fare32
+
+	if err := p.Validate(false); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func fieldError(i int, msg string) error {
+	return fmt.Errorf("%s: %s", Header[i], msg)
+}
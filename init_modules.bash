@@ -11,7 +11,7 @@ trap traperr ERR
 report () {
 	cat >&2 <<-'EOF'
 
-I've successfully initialized all the modules.
+I've successfully tidied all the modules.
 
 	EOF
 }
@@ -38,10 +38,10 @@ go_get_update () {
 }
 
 ini_modules () {
-    modules=('.' 'transport' 'transport/http' 'inmemory' 'implementation' 'cmd/titanic')
+    modules=('.' 'transport' 'transport/http' 'transport/grpc' 'inmemory' 'implementation' 'cmd/titanic')
 
     for i in "${modules[@]}"; do
-        cd $i ; rm -rf go.* ; go mod init ;  cd -  # ; go mod tidy ; GO111MODULE=on go build ; cd -
+        cd $i ; GO111MODULE=on go mod tidy ; cd -
     done
 
 	git add -A . ; git commit -m "modules update" || : ; git push || :
@@ -0,0 +1,275 @@
+package inmemory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"gitlab.com/hyperd/titanic"
+)
+
+// Options configure the persistence of the in-memory storage.
+type Options struct {
+	// Dir is the directory of the write-ahead log and of the snapshots,
+	// created if missing. It must not be shared by several storages.
+	Dir string
+	// Sync is the sync policy of the log, and SyncInterval the interval of
+	// its flushes under the SyncInterval policy.
+	Sync         SyncPolicy
+	SyncInterval time.Duration
+	// SnapshotInterval is the interval of the snapshots compacting the log.
+	// If 0, the log is only compacted when the storage is closed.
+	SnapshotInterval time.Duration
+}
+
+// snapshot is the state of the repository, as of the start of the log of
+// the same generation.
+type snapshot struct {
+	People  []titanic.People `json:"people"`
+	History []titanic.Change `json:"history"`
+}
+
+// NewDurableInmemService returns an in-memory storage persisted in o.Dir:
+// every mutation is appended to a write-ahead log before it is applied, and
+// periodic snapshots of the passengers and of their history compact the
+// log. On start, the storage is rebuilt from the last snapshot and the log
+// following it. The returned function closes the storage, taking a last
+// snapshot.
+func NewDurableInmemService(logger log.Logger, o Options) (titanic.Repository, func() error, error) {
+	if _, err := ParseSyncPolicy(string(o.Sync)); err != nil {
+		return nil, nil, err
+	}
+	if o.Sync == SyncInterval && o.SyncInterval <= 0 {
+		return nil, nil, fmt.Errorf("sync interval must be positive, got %v", o.SyncInterval)
+	}
+	if err := os.MkdirAll(o.Dir, 0755); err != nil {
+		return nil, nil, err
+	}
+
+	r := &repository{
+		m:      map[string]titanic.People{},
+		logger: log.With(logger, "repository", "inmemory", "dir", o.Dir),
+	}
+	gen, err := r.recover(o.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.wal, err = openWAL(o.Dir, gen, o.Sync); err != nil {
+		return nil, nil, err
+	}
+	level.Info(r.logger).Log("msg", "recovered", "people", len(r.m), "changes", len(r.history), "generation", gen)
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go r.run(o, stop, done)
+
+	var once sync.Once
+	return r, func() (err error) {
+		once.Do(func() {
+			close(stop)
+			<-done
+			err = r.snapshot()
+			if cerr := r.wal.close(); err == nil {
+				err = cerr
+			}
+		})
+		return err
+	}, nil
+}
+
+// recover rebuilds the repository from the last snapshot of dir and the
+// logs following it, and returns the generation of the log to append to.
+// Only the torn tail of the last log is dropped: the recovery fails on any
+// other corruption of the logs, or of the snapshot.
+func (r *repository) recover(dir string) (uint64, error) {
+	// The snapshots left half written by a crash are of no use.
+	temps, err := filepath.Glob(filepath.Join(dir, "*"+tempSuffix))
+	if err != nil {
+		return 0, err
+	}
+	for _, temp := range temps {
+		os.Remove(temp)
+	}
+
+	snapshots, err := generations(dir, snapshotPrefix, snapshotSuffix)
+	if err != nil {
+		return 0, err
+	}
+	var gen uint64 = 1
+	if len(snapshots) > 0 {
+		gen = snapshots[len(snapshots)-1]
+		if err := r.load(filepath.Join(dir, snapshotName(gen))); err != nil {
+			return 0, err
+		}
+	}
+
+	logs, err := generations(dir, logPrefix, logSuffix)
+	if err != nil {
+		return 0, err
+	}
+	for len(logs) > 0 && logs[0] < gen {
+		logs = logs[1:] // compacted by the snapshot
+	}
+	last := gen
+	for i, g := range logs {
+		// The logs follow each other from the generation of the snapshot:
+		// the entries following a missing log would be applied without it.
+		if g != gen+uint64(i) {
+			return 0, fmt.Errorf("%s: missing the write-ahead log of generation %d", dir, gen+uint64(i))
+		}
+		entries, err := readLog(filepath.Join(dir, logName(g)), i == len(logs)-1, r.logger)
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range entries {
+			r.apply(e)
+		}
+		last = g
+	}
+	return last, nil
+}
+
+// load loads the snapshot into the repository.
+func (r *repository) load(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var s snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	for _, p := range s.People {
+		r.m[p.ID.String()] = p
+	}
+	r.history = s.History
+	return nil
+}
+
+// run flushes the log and takes the snapshots periodically, until stop is
+// closed.
+func (r *repository) run(o Options, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	var syncs, snapshots <-chan time.Time
+	if o.Sync == SyncInterval {
+		t := time.NewTicker(o.SyncInterval)
+		defer t.Stop()
+		syncs = t.C
+	}
+	if o.SnapshotInterval > 0 {
+		t := time.NewTicker(o.SnapshotInterval)
+		defer t.Stop()
+		snapshots = t.C
+	}
+
+	for {
+		select {
+		case <-syncs:
+			if err := r.wal.sync(); err != nil {
+				level.Error(r.logger).Log("msg", "flushing the write-ahead log", "err", err)
+			}
+		case <-snapshots:
+			if err := r.snapshot(); err != nil {
+				level.Error(r.logger).Log("msg", "taking a snapshot", "err", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// snapshot writes the snapshot of the repository, as of the start of a new
+// log, and removes the files it makes obsolete. The state is copied under
+// the lock, but written without it.
+func (r *repository) snapshot() error {
+	r.mtx.Lock()
+	s := snapshot{
+		People: make([]titanic.People, 0, len(r.m)),
+		// The history is append-only: the entries copied are left alone.
+		History: r.history[:len(r.history):len(r.history)],
+	}
+	for _, p := range r.m {
+		s.People = append(s.People, p)
+	}
+	gen, err := r.wal.rotate()
+	r.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	dir := r.wal.dir
+	if err := writeFile(filepath.Join(dir, snapshotName(gen)), b); err != nil {
+		return err
+	}
+
+	// The older snapshots and logs are removed once the snapshot is safe;
+	// those left by a crash are ignored on start.
+	if err := removeBefore(dir, gen); err != nil {
+		return err
+	}
+	level.Debug(r.logger).Log("msg", "snapshot taken", "people", len(s.People), "changes", len(s.History), "generation", gen)
+	return nil
+}
+
+// removeBefore removes the snapshots and the logs older than the generation.
+func removeBefore(dir string, gen uint64) error {
+	for _, kind := range []struct {
+		prefix, suffix string
+		name           func(uint64) string
+	}{
+		{logPrefix, logSuffix, logName},
+		{snapshotPrefix, snapshotSuffix, snapshotName},
+	} {
+		gens, err := generations(dir, kind.prefix, kind.suffix)
+		if err != nil {
+			return err
+		}
+		for _, g := range gens {
+			if g < gen {
+				if err := os.Remove(filepath.Join(dir, kind.name(g))); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeFile writes the file atomically: to a temporary file, flushed and
+// then renamed, so that a crash leaves either the whole file or none.
+func writeFile(path string, b []byte) error {
+	temp := path + tempSuffix
+	f, err := os.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(temp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(temp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
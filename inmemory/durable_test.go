@@ -0,0 +1,187 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/google/uuid"
+	"gitlab.com/hyperd/titanic"
+)
+
+func openTestStorage(t *testing.T, dir string) (*repository, func() error) {
+	r, closeStorage, err := NewDurableInmemService(log.NewNopLogger(), Options{Dir: dir, Sync: SyncAlways})
+	if err != nil {
+		t.Fatalf("NewDurableInmemService: %v", err)
+	}
+	return r.(*repository), closeStorage
+}
+
+// crash stops the storage without the last snapshot, as a crash would.
+func crash(t *testing.T, r *repository) {
+	if err := r.wal.close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func postTestPeople(t *testing.T, r titanic.Repository, name string) uuid.UUID {
+	id, err := r.PostPeople(context.Background(), titanic.People{Name: name, Sex: "female"})
+	if err != nil {
+		t.Fatalf("PostPeople: %v", err)
+	}
+	return uuid.MustParse(id)
+}
+
+// checkState checks that the storage holds the passengers and the history
+// of want.
+func checkState(t *testing.T, got, want *repository) {
+	for id, p := range want.m {
+		q, ok := got.m[id]
+		if !ok || q.Name != p.Name || q.Version != p.Version || (q.DeletedAt == nil) != (p.DeletedAt == nil) {
+			t.Errorf("passenger %s: got %+v, want %+v", id, q, p)
+		}
+	}
+	if len(got.m) != len(want.m) {
+		t.Errorf("got %d passengers, want %d", len(got.m), len(want.m))
+	}
+	if len(got.history) != len(want.history) {
+		t.Fatalf("got %d changes, want %d", len(got.history), len(want.history))
+	}
+	for i := range want.history {
+		if got.history[i].ID != want.history[i].ID {
+			t.Errorf("change %d: got %v, want %v", i, got.history[i].ID, want.history[i].ID)
+		}
+	}
+}
+
+func TestRecoverSnapshotAndLogs(t *testing.T) {
+	dir, remove := newTestDir(t)
+	defer remove()
+	ctx := context.Background()
+
+	r, _ := openTestStorage(t, dir)
+	amy := postTestPeople(t, r, "Amy")
+	if err := r.snapshot(); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	bea := postTestPeople(t, r, "Bea")
+	if err := r.PatchPeople(ctx, amy, titanic.People{ID: amy, Name: "Amy Pond"}); err != nil {
+		t.Fatalf("PatchPeople: %v", err)
+	}
+	// A rotation without its snapshot, as left by a crash taking it.
+	if _, err := r.wal.rotate(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if _, err := r.DeletePeople(ctx, bea, 0); err != nil {
+		t.Fatalf("DeletePeople: %v", err)
+	}
+	crash(t, r)
+
+	for _, tc := range []struct {
+		prefix, suffix string
+		want           []uint64
+	}{
+		{snapshotPrefix, snapshotSuffix, []uint64{2}},
+		{logPrefix, logSuffix, []uint64{2, 3}},
+	} {
+		gens, err := generations(dir, tc.prefix, tc.suffix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gens, tc.want) {
+			t.Errorf("%s generations: got %v, want %v", tc.prefix, gens, tc.want)
+		}
+	}
+
+	recovered, closeStorage := openTestStorage(t, dir)
+	defer closeStorage()
+	checkState(t, recovered, r)
+	if recovered.wal.gen != 3 {
+		t.Errorf("generation: got %d, want 3", recovered.wal.gen)
+	}
+}
+
+func TestReopenAfterRotation(t *testing.T) {
+	dir, remove := newTestDir(t)
+	defer remove()
+	ctx := context.Background()
+
+	r, closeStorage := openTestStorage(t, dir)
+	amy := postTestPeople(t, r, "Amy")
+	for i := 0; i < 3; i++ {
+		if err := r.snapshot(); err != nil {
+			t.Fatalf("snapshot: %v", err)
+		}
+		if err := r.PatchPeople(ctx, amy, titanic.People{ID: amy, Name: fmt.Sprintf("Amy %d", i)}); err != nil {
+			t.Fatalf("PatchPeople: %v", err)
+		}
+	}
+	if err := closeStorage(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	reopened, closeStorage := openTestStorage(t, dir)
+	checkState(t, reopened, r)
+	postTestPeople(t, reopened, "Bea")
+	if err := closeStorage(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	again, closeStorage := openTestStorage(t, dir)
+	defer closeStorage()
+	checkState(t, again, reopened)
+}
+
+func TestRecoverCorruption(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		spoil func(t *testing.T, dir string)
+	}{
+		{"torn tail of an older log", func(t *testing.T, dir string) {
+			path := filepath.Join(dir, logName(2))
+			corrupt(t, path, fileSize(t, path)-1)
+		}},
+		{"missing log", func(t *testing.T, dir string) {
+			if err := os.Remove(filepath.Join(dir, logName(3))); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"corrupt snapshot", func(t *testing.T, dir string) {
+			if err := ioutil.WriteFile(filepath.Join(dir, snapshotName(2)), []byte(`{"people": [`), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, remove := newTestDir(t)
+			defer remove()
+
+			// Logs of generations 2 to 4 follow the snapshot of
+			// generation 2, each holding an entry.
+			r, _ := openTestStorage(t, dir)
+			postTestPeople(t, r, "Amy")
+			if err := r.snapshot(); err != nil {
+				t.Fatalf("snapshot: %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				postTestPeople(t, r, "Bea")
+				if i < 2 {
+					if _, err := r.wal.rotate(); err != nil {
+						t.Fatalf("rotate: %v", err)
+					}
+				}
+			}
+			crash(t, r)
+			tc.spoil(t, dir)
+
+			if _, _, err := NewDurableInmemService(log.NewNopLogger(), Options{Dir: dir, Sync: SyncAlways}); err == nil {
+				t.Errorf("NewDurableInmemService: got no error")
+			}
+		})
+	}
+}
@@ -1,3 +1,11 @@
 module gitlab.com/hyperd/titanic/inmemory
 
 go 1.13
+
+require (
+	github.com/go-kit/kit v0.9.0
+	github.com/google/uuid v1.1.1
+	gitlab.com/hyperd/titanic v0.0.0-00010101000000-000000000000
+)
+
+replace gitlab.com/hyperd/titanic => ..
@@ -1,26 +1,35 @@
 package inmemory
 
 import (
+	"bytes"
 	"context"
-	"errors"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/google/uuid"
 	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/audit"
+	"gitlab.com/hyperd/titanic/tracing"
 )
 
-// Response errors
+// Response errors, shared with the service so that the transports map them
+// to the proper status codes.
 var (
-	ErrInconsistentID = errors.New("inconsistent ID")
-	ErrAlreadyExists  = errors.New("already exists")
-	ErrNotFound       = errors.New("not found")
+	ErrInconsistentID  = titanic.ErrInconsistentIDs
+	ErrAlreadyExists   = titanic.ErrAlreadyExists
+	ErrNotFound        = titanic.ErrNotFound
+	ErrVersionConflict = titanic.ErrVersionConflict
 )
 
 type repository struct {
-	mtx    sync.RWMutex
-	m      map[string]titanic.People
-	logger log.Logger
+	mtx     sync.RWMutex
+	m       map[string]titanic.People
+	history []titanic.Change // append-only
+	wal     *wal             // nil unless durable
+	logger  log.Logger
 }
 
 // NewInmemService returns an in-memory storage
@@ -31,50 +40,112 @@ func NewInmemService(logger log.Logger) (titanic.Repository, error) {
 	}, nil
 }
 
-func (r *repository) PostPeople(ctx context.Context, p titanic.People) (string, error) {
+func (r *repository) PostPeople(ctx context.Context, p titanic.People) (_ string, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.PostPeople", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	// id, err := uuid.Parse(vars["uuid"])
 	id := uuid.New()
 
 	p.ID = id
+	p.Version = 1
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+	p.DeletedAt = nil
 
 	if _, ok := r.m[p.ID.String()]; ok {
 		return "", ErrAlreadyExists // POST = create, don't overwrite
 	}
-	r.m[p.ID.String()] = p
+	if err := r.record(ctx, titanic.ActionCreate, id, nil, p); err != nil {
+		return "", err
+	}
 	return id.String(), nil
 }
 
-func (r *repository) GetPeopleByID(ctx context.Context, uuid uuid.UUID) (titanic.People, error) {
+func (r *repository) PostPeopleBatch(ctx context.Context, people []titanic.People) (_ []string, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.PostPeopleBatch", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	ids := make([]string, len(people))
+	batch := make(map[string]titanic.People, len(people))
+	now := time.Now()
+	for i, p := range people {
+		p.ID = uuid.New()
+		p.Version = 1
+		p.CreatedAt, p.UpdatedAt, p.DeletedAt = now, now, nil
+		if _, ok := r.m[p.ID.String()]; ok {
+			return nil, ErrAlreadyExists // POST = create, don't overwrite
+		}
+		ids[i] = p.ID.String()
+		batch[ids[i]] = p
+	}
+
+	// A failed batch leaves the repository untouched.
+	var e entry
+	for _, id := range ids {
+		p := batch[id]
+		e.Changes = append(e.Changes, audit.NewChange(ctx, titanic.ActionCreate, p.ID, nil, &p))
+	}
+	if err := r.commit(e); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *repository) GetPeopleByID(ctx context.Context, uuid uuid.UUID) (_ titanic.People, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.GetPeopleByID", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
 	r.mtx.RLock()
 	defer r.mtx.RUnlock()
-	p, ok := r.m[uuid.String()]
+	p, ok := r.live(uuid.String())
 	if !ok {
 		return titanic.People{}, ErrNotFound
 	}
 	return p, nil
 }
 
-func (r *repository) PutPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) error {
+func (r *repository) PutPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) (_ bool, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.PutPeople", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
 	if p.ID.String() == "" {
-		return ErrInconsistentID
+		return false, ErrInconsistentID
 	}
 
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
-	existing, ok := r.m[uuid.String()]
-	if !ok {
-		p.ID = uuid // PUT can create
+	existing, ok := r.live(uuid.String())
+	if !matches(p.Version, existing, ok) {
+		return false, ErrVersionConflict // a missing passenger matches no version
+	}
+	var before *titanic.People
+	if ok {
+		before = &existing
+	} else {
+		if _, deleted := r.m[uuid.String()]; deleted {
+			return false, ErrNotFound // a deleted passenger must be restored first
+		}
+		existing.ID = uuid // PUT can create
+		existing.CreatedAt = time.Now()
 	}
 
-	r.m[uuid.String()] = setPeople(p, existing)
-
-	return nil
+	updated := setPeople(p, existing)
+	updated.Version = existing.Version + 1
+	updated.UpdatedAt = time.Now()
+	return !ok, r.record(ctx, titanic.ActionPut, uuid, before, updated)
 }
 
-func (r *repository) PatchPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) error {
+func (r *repository) PatchPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) (err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.PatchPeople", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
 	if p.ID.String() == "" {
 		return ErrInconsistentID
 	}
@@ -82,38 +153,406 @@ func (r *repository) PatchPeople(ctx context.Context, uuid uuid.UUID, p titanic.
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
-	existing, ok := r.m[uuid.String()]
+	existing, ok := r.live(uuid.String())
+	if !matches(p.Version, existing, ok) {
+		return ErrVersionConflict
+	}
 	if !ok {
 		return ErrNotFound // PATCH = update existing, don't create
 	}
 
-	r.m[uuid.String()] = setPeople(p, existing)
-	return nil
+	updated := setPeople(p, existing)
+	updated.Version = existing.Version + 1
+	updated.UpdatedAt = time.Now()
+	return r.record(ctx, titanic.ActionPatch, uuid, &existing, updated)
 }
 
-func (r *repository) DeletePeople(ctx context.Context, uuid uuid.UUID) (string, error) {
+func (r *repository) DeletePeople(ctx context.Context, uuid uuid.UUID, version int) (_ string, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.DeletePeople", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
-	if _, ok := r.m[uuid.String()]; !ok {
+	existing, ok := r.live(uuid.String())
+	if !matches(version, existing, ok) {
+		return uuid.String(), ErrVersionConflict
+	}
+	if !ok {
 		return uuid.String(), ErrNotFound
 	}
-	delete(r.m, uuid.String())
-	return uuid.String(), nil
+
+	// The passenger is only marked as deleted, until purged, as a new
+	// version of it: the versions read before no longer match.
+	deleted := existing
+	now := time.Now()
+	deleted.DeletedAt = &now
+	deleted.Version++
+	return uuid.String(), r.record(ctx, titanic.ActionDelete, uuid, &existing, deleted)
 }
 
-func (r *repository) GetPeople(ctx context.Context) ([]titanic.People, error) {
+func (r *repository) RestorePeople(ctx context.Context, uuid uuid.UUID) (err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.RestorePeople", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	p, ok := r.m[uuid.String()]
+	if !ok {
+		return ErrNotFound
+	}
+	if p.DeletedAt == nil {
+		return nil // restoring a passenger that isn't deleted has no effect
+	}
+	restored := p
+	restored.DeletedAt = nil
+	restored.Version++
+	return r.record(ctx, titanic.ActionRestore, uuid, &p, restored)
+}
+
+func (r *repository) PurgePeople(ctx context.Context, before time.Time) (_ int, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.PurgePeople", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
+	// The history of the purged passengers is kept.
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	var e entry
+	for _, p := range r.m {
+		if p.DeletedAt != nil && p.DeletedAt.Before(before) {
+			e.Purged = append(e.Purged, p.ID)
+		}
+	}
+	if len(e.Purged) == 0 {
+		return 0, nil
+	}
+	if err := r.commit(e); err != nil {
+		return 0, err
+	}
+	return len(e.Purged), nil
+}
+
+func (r *repository) GetPeople(ctx context.Context, q titanic.PeopleQuery) (_ titanic.PeoplePage, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.GetPeople", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
+	p := r.list(q)
+
+	page := titanic.PeoplePage{Total: len(p)}
+
+	start := q.Offset
+	if q.Cursor != "" {
+		c, err := q.DecodeCursor()
+		if err != nil {
+			return page, err
+		}
+		start = sort.Search(len(p), func(i int) bool {
+			return compareCursor(c, p[i], q.Sort) < 0
+		})
+	}
+	if start > len(p) {
+		start = len(p)
+	}
+
+	end := start + q.Limit
+	if end < len(p) && end > start {
+		page.NextCursor = titanic.NewCursor(p[end-1], q.Sort).String()
+	} else {
+		end = len(p)
+	}
+
+	page.People = p[start:end]
+	return page, nil
+}
+
+func (r *repository) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) (err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.StreamPeople", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
+	// The passengers are copied, so that slow consumers don't hold the lock.
+	for _, p := range r.list(q) {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repository) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (_ titanic.Statistics, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.GetStatistics", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
+	type aggregate struct {
+		group       titanic.StatisticsGroup
+		known       int
+		ages, fares []float64
+	}
+
+	groups := map[string]*aggregate{}
+	for _, p := range r.list(titanic.PeopleQuery{Filter: q.Filter}) {
+		var g titanic.StatisticsGroup
+		if q.Grouped(titanic.GroupByPclass) && p.Pclass != nil {
+			pclass := *p.Pclass
+			g.Pclass = &pclass
+		}
+		if q.Grouped(titanic.GroupBySex) {
+			sex := p.Sex
+			g.Sex = &sex
+		}
+		if q.Grouped(titanic.GroupByAgeBand) && p.Age != nil {
+			band := titanic.NewAgeBand(*p.Age, q.AgeBandWidth)
+			g.AgeBand = &band
+		}
+
+		a, ok := groups[g.Key()]
+		if !ok {
+			a = &aggregate{group: g}
+			groups[g.Key()] = a
+		}
+
+		a.group.Count++
+		if p.Survived != nil {
+			a.known++
+			if *p.Survived {
+				a.group.Survived++
+			}
+		}
+		if p.Age != nil {
+			a.ages = append(a.ages, float64(*p.Age))
+		}
+		if p.Fare != nil {
+			a.fares = append(a.fares, float64(*p.Fare))
+		}
+	}
+
+	stats := titanic.Statistics{GroupBy: q.GroupBy, Groups: []titanic.StatisticsGroup{}}
+	for _, a := range groups {
+		g := a.group
+		if a.known > 0 {
+			rate := float64(g.Survived) / float64(a.known)
+			g.SurvivalRate = &rate
+		}
+		g.MeanAge, g.MedianAge = meanMedian(a.ages)
+		g.MeanFare, g.MedianFare = meanMedian(a.fares)
+		stats.Groups = append(stats.Groups, g)
+	}
+	titanic.SortGroups(stats.Groups)
+
+	return stats, nil
+}
+
+// meanMedian returns the mean and the median of the values, nil when empty.
+// The median of an even number of values is the mean of the middle two.
+func meanMedian(values []float64) (mean, median *float64) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	avg := sum / float64(len(values))
+
+	sort.Float64s(values)
+	mid := values[len(values)/2]
+	if len(values)%2 == 0 {
+		mid = (values[len(values)/2-1] + mid) / 2
+	}
+
+	return &avg, &mid
+}
+
+// list returns the passengers matching the query filter, sorted.
+func (r *repository) list(q titanic.PeopleQuery) []titanic.People {
 	r.mtx.RLock()
 	defer r.mtx.RUnlock()
 
-	p := []titanic.People{}
+	p := make([]titanic.People, 0, len(r.m))
 	for _, value := range r.m {
-		p = append(p, value)
+		if value.DeletedAt != nil && !q.IncludeDeleted {
+			continue
+		}
+		if matchPeople(q.Filter, value) {
+			p = append(p, value)
+		}
+	}
+
+	// Order by the sort keys and then by ID, the same way the SQL
+	// repositories do, so that pages are stable between calls.
+	sort.Slice(p, func(i, j int) bool {
+		return comparePeople(p[i], p[j], q.Sort) < 0
+	})
+
+	return p
+}
+
+func (r *repository) GetPeopleHistory(ctx context.Context, uuid uuid.UUID) (_ []titanic.Change, err error) {
+	_, span := tracing.StartSpan(ctx, "inmemory.GetPeopleHistory", "db.system", "inmemory")
+	defer func() { span.Finish(err) }()
+
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var history []titanic.Change
+	for _, c := range r.history {
+		if c.PeopleID == uuid {
+			history = append(history, c)
+		}
+	}
+	if len(history) == 0 {
+		return nil, ErrNotFound
 	}
+	return history, nil
+}
+
+// record commits the change to the passenger, after which the passenger is
+// after. The caller must hold the lock.
+func (r *repository) record(ctx context.Context, action string, id uuid.UUID, before *titanic.People, after titanic.People) error {
+	return r.commit(entry{Changes: []titanic.Change{audit.NewChange(ctx, action, id, before, &after)}})
+}
 
-	if p == nil {
-		return []titanic.People{}, ErrNotFound
+// commit applies the entry, once appended to the write-ahead log of a
+// durable repository: a mutation failing to reach the log leaves the
+// repository untouched. The caller must hold the lock.
+func (r *repository) commit(e entry) error {
+	if r.wal != nil {
+		if err := r.wal.append(e); err != nil {
+			return err
+		}
 	}
-	return p, nil
+	r.apply(e)
+	return nil
+}
+
+// apply applies the entry, committed or replayed from the log: the changes
+// set the passengers and are appended to the history, the purged
+// passengers are removed. The caller must hold the lock.
+func (r *repository) apply(e entry) {
+	for _, c := range e.Changes {
+		r.m[c.PeopleID.String()] = *c.After
+		r.history = append(r.history, c)
+	}
+	for _, id := range e.Purged {
+		delete(r.m, id.String())
+	}
+}
+
+// live returns the passenger, unless it is missing or deleted. The caller
+// must hold the lock.
+func (r *repository) live(id string) (titanic.People, bool) {
+	p, ok := r.m[id]
+	if !ok || p.DeletedAt != nil {
+		return titanic.People{}, false
+	}
+	return p, true
+}
+
+// matches tells whether the passenger found, if any, is at the version
+// required by an update: 0 requires none, and titanic.AnyVersion only that
+// the passenger exists.
+func matches(version int, existing titanic.People, ok bool) bool {
+	switch version {
+	case 0:
+		return true
+	case titanic.AnyVersion:
+		return ok
+	}
+	return ok && version == existing.Version
+}
+
+// matchPeople evaluates the filter against a single passenger, with the same
+// semantics as the WHERE clauses built by the SQL repositories: a passenger
+// with no value for a filtered field never matches.
+func matchPeople(f titanic.PeopleFilter, p titanic.People) bool {
+	if f.Survived != nil && (p.Survived == nil || *p.Survived != *f.Survived) {
+		return false
+	}
+
+	if f.Pclass != nil && (p.Pclass == nil || *p.Pclass != *f.Pclass) {
+		return false
+	}
+
+	if f.Sex != "" && p.Sex != f.Sex {
+		return false
+	}
+
+	if f.AgeMin != nil && (p.Age == nil || *p.Age < *f.AgeMin) {
+		return false
+	}
+
+	if f.AgeMax != nil && (p.Age == nil || *p.Age > *f.AgeMax) {
+		return false
+	}
+
+	if f.FareMin != nil && (p.Fare == nil || *p.Fare < *f.FareMin) {
+		return false
+	}
+
+	if f.FareMax != nil && (p.Fare == nil || *p.Fare > *f.FareMax) {
+		return false
+	}
+
+	return true
+}
+
+// comparePeople orders two passengers by the sort keys, then by ID.
+func comparePeople(a, b titanic.People, keys []titanic.SortKey) int {
+	for _, k := range keys {
+		if c := compareKey(k, k.Value(a), k.Value(b)); c != 0 {
+			return c
+		}
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+// compareCursor orders the position marked by the cursor against a passenger.
+func compareCursor(cursor titanic.Cursor, p titanic.People, keys []titanic.SortKey) int {
+	for i, k := range keys {
+		if c := compareKey(k, cursor.Values[i], k.Value(p)); c != 0 {
+			return c
+		}
+	}
+	return bytes.Compare(cursor.ID[:], p.ID[:])
+}
+
+// compareKey compares two values of a sort key field. Missing values sort
+// first, as NULLs do in CockroachDB.
+func compareKey(k titanic.SortKey, a, b interface{}) (c int) {
+	if k.Desc {
+		defer func() { c = -c }()
+	}
+
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch a := a.(type) {
+	case bool:
+		b := b.(bool)
+		if a == b {
+			return 0
+		}
+		if !a {
+			return -1
+		}
+		return 1
+	case float64:
+		b := b.(float64)
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
 }
 
 func setPeople(p titanic.People, existing titanic.People) titanic.People {
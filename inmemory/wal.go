@@ -0,0 +1,326 @@
+package inmemory
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/google/uuid"
+	"gitlab.com/hyperd/titanic"
+)
+
+// The log holds the entries of the mutations of the repository, each one
+// framed by the length and the CRC-32C checksum of its JSON payload, in
+// little endian:
+//
+//	+----------------+----------------+-----------------------+
+//	| length, uint32 | crc32c, uint32 | payload, length bytes |
+//	+----------------+----------------+-----------------------+
+//
+// An entry is appended with a single write, and the logs but the last one
+// are flushed, so that a crash tears at most the last entry of the last log,
+// which the reader truncates. Any other invalid entry is a corruption.
+const (
+	entryHeaderSize = 8
+	maxEntrySize    = 64 << 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// errTornEntry reports an entry ending the log which is cut short, or
+	// doesn't match its checksum, as left by a crash.
+	errTornEntry = errors.New("torn entry")
+	// errCorruptEntry reports an entry which doesn't match its checksum,
+	// followed by other entries.
+	errCorruptEntry = errors.New("corrupt entry")
+)
+
+// entry is a mutation of the repository, applied as a whole: the changes to
+// the passengers, or the passengers purged.
+type entry struct {
+	Changes []titanic.Change `json:"changes,omitempty"`
+	Purged  []uuid.UUID      `json:"purged,omitempty"`
+}
+
+// SyncPolicy tells when the log is flushed to the disk, trading the latency
+// of the mutations for the mutations lost if the machine crashes. None is
+// lost if only the process crashes.
+type SyncPolicy string
+
+// Sync policies of the log.
+const (
+	// SyncAlways flushes every entry before the mutation returns.
+	SyncAlways SyncPolicy = "always"
+	// SyncInterval flushes the entries periodically, losing the last
+	// interval of mutations at most.
+	SyncInterval SyncPolicy = "interval"
+	// SyncNever leaves the flushes to the operating system.
+	SyncNever SyncPolicy = "never"
+)
+
+// ParseSyncPolicy parses a sync policy: always, interval or never.
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	switch p := SyncPolicy(s); p {
+	case SyncAlways, SyncInterval, SyncNever:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown sync policy %q", s)
+}
+
+// wal is the write-ahead log of the repository. It is split in generations:
+// the log of generation n holds the entries following the snapshot of
+// generation n, if any, so that a snapshot makes the older logs obsolete.
+type wal struct {
+	mtx    sync.Mutex
+	dir    string
+	policy SyncPolicy
+	gen    uint64
+	f      *os.File
+	size   int64 // of the valid entries of f
+	dirty  bool  // entries were written since the last flush
+	err    error // the log is broken, and refuses the entries
+}
+
+// openWAL opens the log of the generation, created if missing, for
+// appending.
+func openWAL(dir string, gen uint64, policy SyncPolicy) (*wal, error) {
+	w := &wal{dir: dir, policy: policy}
+	if err := w.open(gen); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *wal) open(gen uint64) error {
+	f, err := os.OpenFile(filepath.Join(w.dir, logName(gen)), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := syncDir(w.dir); err != nil {
+		f.Close()
+		return err
+	}
+	w.gen, w.f, w.size, w.dirty = gen, f, fi.Size(), false
+	return nil
+}
+
+// append writes the entry to the log, and flushes it if the policy says
+// so. The entry must not be applied unless it is appended.
+func (w *wal) append(e entry) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxEntrySize {
+		return fmt.Errorf("entry of %d bytes exceeds the %d bytes allowed", len(payload), maxEntrySize)
+	}
+	buf := make([]byte, entryHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.Checksum(payload, crcTable))
+	copy(buf[entryHeaderSize:], payload)
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if _, err := w.f.Write(buf); err != nil {
+		// Drop what was written of the entry, lest the entries following
+		// it be taken for a torn tail.
+		if terr := w.f.Truncate(w.size); terr != nil {
+			w.err = fmt.Errorf("write-ahead log: %v", terr)
+		}
+		return err
+	}
+	w.size += int64(len(buf))
+	if w.policy == SyncAlways {
+		return w.flush()
+	}
+	w.dirty = true
+	return nil
+}
+
+// sync flushes the entries written since the last flush.
+func (w *wal) sync() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.err != nil || !w.dirty {
+		return w.err
+	}
+	return w.flush()
+}
+
+// flush flushes the log, which is broken if it fails, as whether the
+// entries reached the disk is unknown. The caller must hold the lock.
+func (w *wal) flush() error {
+	if err := w.f.Sync(); err != nil {
+		w.err = fmt.Errorf("write-ahead log: %v", err)
+		return err
+	}
+	w.dirty = false
+	return nil
+}
+
+// rotate starts the log of the next generation, and returns the generation.
+// The log left is flushed, so that only the last log can have a torn tail.
+func (w *wal) rotate() (uint64, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	if err := w.flush(); err != nil {
+		return 0, err
+	}
+	f := w.f
+	if err := w.open(w.gen + 1); err != nil {
+		return 0, err
+	}
+	f.Close()
+	return w.gen, nil
+}
+
+// close flushes and closes the log.
+func (w *wal) close() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	err := w.err
+	if err == nil {
+		err = w.flush()
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		w.err = errors.New("write-ahead log closed")
+	}
+	return err
+}
+
+// readLog reads the entries of the log. If the log is the last one, the
+// only one a crash can tear, a torn entry ends it: the file is truncated
+// before it, so that the entries appended next follow the last valid entry.
+// Any other invalid entry fails the read, as the entries following it would
+// be applied without it.
+func readLog(path string, last bool, logger log.Logger) ([]entry, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	offset := 0
+	for offset < len(b) {
+		payload, err := readEntry(b[offset:])
+		if err == errTornEntry && last {
+			level.Warn(logger).Log("msg", "truncating the torn tail of the write-ahead log", "file", path, "offset", offset, "dropped", len(b)-offset)
+			if err := os.Truncate(path, int64(offset)); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: entry at offset %d: %v", path, offset, err)
+		}
+		var e entry
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, fmt.Errorf("%s: entry at offset %d: %v", path, offset, err)
+		}
+		entries = append(entries, e)
+		offset += entryHeaderSize + len(payload)
+	}
+	return entries, nil
+}
+
+// readEntry returns the payload of the entry at the start of b, or
+// errTornEntry if it is incomplete, or doesn't match its checksum while
+// ending b, and errCorruptEntry if it doesn't match its checksum otherwise.
+func readEntry(b []byte) ([]byte, error) {
+	if len(b) < entryHeaderSize {
+		return nil, errTornEntry
+	}
+	n := int64(binary.LittleEndian.Uint32(b[0:4]))
+	if n > int64(len(b)-entryHeaderSize) {
+		return nil, errTornEntry
+	}
+	payload := b[entryHeaderSize : entryHeaderSize+int(n)]
+	if n > maxEntrySize || crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(b[4:8]) {
+		if entryHeaderSize+int(n) == len(b) {
+			return nil, errTornEntry
+		}
+		return nil, errCorruptEntry
+	}
+	return payload, nil
+}
+
+// The files of the repository are named after their generation, in
+// hexadecimal so that they sort by generation.
+const (
+	logPrefix      = "wal-"
+	logSuffix      = ".log"
+	snapshotPrefix = "snapshot-"
+	snapshotSuffix = ".json"
+	tempSuffix     = ".tmp"
+)
+
+func logName(gen uint64) string {
+	return fmt.Sprintf("%s%016x%s", logPrefix, gen, logSuffix)
+}
+
+func snapshotName(gen uint64) string {
+	return fmt.Sprintf("%s%016x%s", snapshotPrefix, gen, snapshotSuffix)
+}
+
+// generations returns the generations of the files of the directory with
+// the prefix and the suffix, in order.
+func generations(dir, prefix, suffix string) ([]uint64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var gens []uint64
+	for _, fi := range files {
+		name := fi.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		gen, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix), 16, 64)
+		if err != nil {
+			continue
+		}
+		gens = append(gens, gen)
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i] < gens[j] })
+	return gens, nil
+}
+
+// syncDir flushes the directory, so that the files created or renamed in it
+// survive a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package inmemory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/google/uuid"
+)
+
+// newTestDir creates a directory of its own for the test, along with the
+// function removing it.
+func newTestDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "inmemory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// writeTestLog appends the entries to the log of the generation, and
+// returns the offsets of the entries, followed by the size of the log.
+func writeTestLog(t *testing.T, dir string, gen uint64, entries ...entry) []int64 {
+	w, err := openWAL(dir, gen, SyncAlways)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsets := []int64{w.size}
+	for _, e := range entries {
+		if err := w.append(e); err != nil {
+			t.Fatal(err)
+		}
+		offsets = append(offsets, w.size)
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	return offsets
+}
+
+func testEntries(n int) []entry {
+	entries := make([]entry, n)
+	for i := range entries {
+		entries[i] = entry{Purged: []uuid.UUID{uuid.New()}}
+	}
+	return entries
+}
+
+// corrupt flips a byte of the file.
+func corrupt(t *testing.T, path string, offset int64) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[offset] ^= 0xff
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
+
+func TestWALRoundTrip(t *testing.T) {
+	dir, remove := newTestDir(t)
+	defer remove()
+
+	want := testEntries(3)
+	writeTestLog(t, dir, 1, want[:2]...)
+	// The log is appended to when opened again.
+	writeTestLog(t, dir, 1, want[2:]...)
+
+	got, err := readLog(filepath.Join(dir, logName(1)), true, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("readLog: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readLog: got %v, want %v", got, want)
+	}
+}
+
+func TestReadLogTornTail(t *testing.T) {
+	entries := testEntries(3)
+	for _, tc := range []struct {
+		name string
+		tear func(path string, offsets []int64)
+	}{
+		{"cut header", func(path string, offsets []int64) {
+			os.Truncate(path, offsets[2]+entryHeaderSize/2)
+		}},
+		{"cut payload", func(path string, offsets []int64) {
+			os.Truncate(path, offsets[3]-1)
+		}},
+		{"checksum mismatch", func(path string, offsets []int64) {
+			corrupt(t, path, offsets[3]-1)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, remove := newTestDir(t)
+			defer remove()
+			path := filepath.Join(dir, logName(1))
+			offsets := writeTestLog(t, dir, 1, entries...)
+			tc.tear(path, offsets)
+
+			got, err := readLog(path, true, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("readLog: %v", err)
+			}
+			if !reflect.DeepEqual(got, entries[:2]) {
+				t.Errorf("readLog: got %v, want %v", got, entries[:2])
+			}
+			if size := fileSize(t, path); size != offsets[2] {
+				t.Errorf("size: got %d, want %d", size, offsets[2])
+			}
+
+			// The entries appended next follow the last valid entry.
+			writeTestLog(t, dir, 1, entries[2])
+			got, err = readLog(path, true, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("readLog: %v", err)
+			}
+			if !reflect.DeepEqual(got, entries) {
+				t.Errorf("readLog: got %v, want %v", got, entries)
+			}
+		})
+	}
+}
+
+func TestReadLogCorruption(t *testing.T) {
+	entries := testEntries(3)
+	for _, tc := range []struct {
+		name   string
+		last   bool
+		offset func(offsets []int64) int64
+	}{
+		{"checksum mismatch followed by entries", true, func(offsets []int64) int64 { return offsets[1] - 1 }},
+		{"corrupt checksum followed by entries", true, func(offsets []int64) int64 { return offsets[0] + 4 }},
+		{"torn tail of an older log", false, func(offsets []int64) int64 { return offsets[3] - 1 }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, remove := newTestDir(t)
+			defer remove()
+			path := filepath.Join(dir, logName(1))
+			offsets := writeTestLog(t, dir, 1, entries...)
+			corrupt(t, path, tc.offset(offsets))
+
+			if _, err := readLog(path, tc.last, log.NewNopLogger()); err == nil || !strings.Contains(err.Error(), logName(1)) {
+				t.Errorf("readLog: got %v, want an error", err)
+			}
+			// The log is left as is.
+			if size := fileSize(t, path); size != offsets[3] {
+				t.Errorf("size: got %d, want %d", size, offsets[3])
+			}
+		})
+	}
+}
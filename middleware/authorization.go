@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/auth"
+)
+
+// AuthorizationMiddleware provides a Middleware restricting the writes to
+// the editors, and the deletions to the admins, as authenticated by the
+// claims carried by the context. Reads are open to anonymous callers, but not
+// to those presenting an invalid token.
+func AuthorizationMiddleware() Middleware {
+	return func(next titanic.Service) titanic.Service {
+		return &authorizationMiddleware{
+			next: next,
+		}
+	}
+}
+
+type authorizationMiddleware struct {
+	next titanic.Service
+}
+
+// authorize fails with titanic.ErrUnauthenticated unless the caller is
+// authenticated, or anonymous for an empty role, and with
+// titanic.ErrForbidden unless granted the role.
+func (mw authorizationMiddleware) authorize(ctx context.Context, role string) error {
+	claims, err := auth.FromContext(ctx)
+	switch {
+	case err == auth.ErrMissingToken && role == "":
+		return nil
+	case err != nil:
+		return titanic.ErrUnauthenticated
+	case role != "" && !claims.HasRole(role):
+		return titanic.ErrForbidden
+	}
+	return nil
+}
+
+func (mw authorizationMiddleware) PostPeople(ctx context.Context, p titanic.People) (string, error) {
+	if err := mw.authorize(ctx, auth.RoleEditor); err != nil {
+		return "", err
+	}
+	return mw.next.PostPeople(ctx, p)
+}
+
+func (mw authorizationMiddleware) PostPeopleBatch(ctx context.Context, people []titanic.People) ([]string, error) {
+	if err := mw.authorize(ctx, auth.RoleEditor); err != nil {
+		return nil, err
+	}
+	return mw.next.PostPeopleBatch(ctx, people)
+}
+
+func (mw authorizationMiddleware) GetPeopleByID(ctx context.Context, uuid uuid.UUID) (titanic.People, error) {
+	if err := mw.authorize(ctx, ""); err != nil {
+		return titanic.People{}, err
+	}
+	return mw.next.GetPeopleByID(ctx, uuid)
+}
+
+func (mw authorizationMiddleware) PutPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) error {
+	if err := mw.authorize(ctx, auth.RoleEditor); err != nil {
+		return err
+	}
+	return mw.next.PutPeople(ctx, uuid, p)
+}
+
+func (mw authorizationMiddleware) PatchPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) error {
+	if err := mw.authorize(ctx, auth.RoleEditor); err != nil {
+		return err
+	}
+	return mw.next.PatchPeople(ctx, uuid, p)
+}
+
+func (mw authorizationMiddleware) DeletePeople(ctx context.Context, uuid uuid.UUID, version int) (string, error) {
+	if err := mw.authorize(ctx, auth.RoleAdmin); err != nil {
+		return uuid.String(), err
+	}
+	return mw.next.DeletePeople(ctx, uuid, version)
+}
+
+// RestorePeople undoes a deletion, and is restricted the same way.
+func (mw authorizationMiddleware) RestorePeople(ctx context.Context, uuid uuid.UUID) error {
+	if err := mw.authorize(ctx, auth.RoleAdmin); err != nil {
+		return err
+	}
+	return mw.next.RestorePeople(ctx, uuid)
+}
+
+func (mw authorizationMiddleware) GetPeopleHistory(ctx context.Context, uuid uuid.UUID) ([]titanic.Change, error) {
+	if err := mw.authorize(ctx, ""); err != nil {
+		return nil, err
+	}
+	return mw.next.GetPeopleHistory(ctx, uuid)
+}
+
+func (mw authorizationMiddleware) GetPeople(ctx context.Context, q titanic.PeopleQuery) (titanic.PeoplePage, error) {
+	if err := mw.authorize(ctx, ""); err != nil {
+		return titanic.PeoplePage{}, err
+	}
+	return mw.next.GetPeople(ctx, q)
+}
+
+func (mw authorizationMiddleware) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) error {
+	if err := mw.authorize(ctx, ""); err != nil {
+		return err
+	}
+	return mw.next.StreamPeople(ctx, q, fn)
+}
+
+func (mw authorizationMiddleware) WatchPeople(ctx context.Context, lastEventID uint64, fn func(titanic.Event) error) error {
+	if err := mw.authorize(ctx, ""); err != nil {
+		return err
+	}
+	return mw.next.WatchPeople(ctx, lastEventID, fn)
+}
+
+func (mw authorizationMiddleware) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (titanic.Statistics, error) {
+	if err := mw.authorize(ctx, ""); err != nil {
+		return titanic.Statistics{}, err
+	}
+	return mw.next.GetStatistics(ctx, q)
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/google/uuid"
+
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/events"
+)
+
+// errorLabel names the error in the metrics: by the code of the service
+// error it is or wraps, as titanic.ErrorCode returns it, any other error
+// being counted as "internal", which keeps the label cardinality bounded.
+func errorLabel(err error) string {
+	if errors.Is(err, events.ErrOverflow) {
+		return "event_overflow"
+	}
+	return string(titanic.ErrorCode(err))
+}
+
+// InstrumentingMiddleware provides a Middleware recording, per method, the
+// number of requests, the number of errors labelled by error, and the
+// request latency in seconds.
+func InstrumentingMiddleware(requestCount, errorCount metrics.Counter, requestLatency metrics.Histogram) Middleware {
+	return func(next titanic.Service) titanic.Service {
+		return &instrumentingMiddleware{
+			next:           next,
+			requestCount:   requestCount,
+			errorCount:     errorCount,
+			requestLatency: requestLatency,
+		}
+	}
+}
+
+type instrumentingMiddleware struct {
+	next           titanic.Service
+	requestCount   metrics.Counter
+	errorCount     metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+func (mw instrumentingMiddleware) instrument(method string, begin time.Time, err error) {
+	mw.requestCount.With("method", method).Add(1)
+	mw.requestLatency.With("method", method).Observe(time.Since(begin).Seconds())
+	if err != nil {
+		mw.errorCount.With("method", method, "error", errorLabel(err)).Add(1)
+	}
+}
+
+func (mw instrumentingMiddleware) PostPeople(ctx context.Context, p titanic.People) (id string, err error) {
+	defer func(begin time.Time) { mw.instrument("PostPeople", begin, err) }(time.Now())
+	return mw.next.PostPeople(ctx, p)
+}
+
+func (mw instrumentingMiddleware) PostPeopleBatch(ctx context.Context, people []titanic.People) (ids []string, err error) {
+	defer func(begin time.Time) { mw.instrument("PostPeopleBatch", begin, err) }(time.Now())
+	return mw.next.PostPeopleBatch(ctx, people)
+}
+
+func (mw instrumentingMiddleware) GetPeopleByID(ctx context.Context, uuid uuid.UUID) (p titanic.People, err error) {
+	defer func(begin time.Time) { mw.instrument("GetPeopleByID", begin, err) }(time.Now())
+	return mw.next.GetPeopleByID(ctx, uuid)
+}
+
+func (mw instrumentingMiddleware) PutPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) (err error) {
+	defer func(begin time.Time) { mw.instrument("PutPeople", begin, err) }(time.Now())
+	return mw.next.PutPeople(ctx, uuid, p)
+}
+
+func (mw instrumentingMiddleware) PatchPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) (err error) {
+	defer func(begin time.Time) { mw.instrument("PatchPeople", begin, err) }(time.Now())
+	return mw.next.PatchPeople(ctx, uuid, p)
+}
+
+func (mw instrumentingMiddleware) DeletePeople(ctx context.Context, uuid uuid.UUID, version int) (id string, err error) {
+	defer func(begin time.Time) { mw.instrument("DeletePeople", begin, err) }(time.Now())
+	return mw.next.DeletePeople(ctx, uuid, version)
+}
+
+func (mw instrumentingMiddleware) RestorePeople(ctx context.Context, uuid uuid.UUID) (err error) {
+	defer func(begin time.Time) { mw.instrument("RestorePeople", begin, err) }(time.Now())
+	return mw.next.RestorePeople(ctx, uuid)
+}
+
+func (mw instrumentingMiddleware) GetPeopleHistory(ctx context.Context, uuid uuid.UUID) (history []titanic.Change, err error) {
+	defer func(begin time.Time) { mw.instrument("GetPeopleHistory", begin, err) }(time.Now())
+	return mw.next.GetPeopleHistory(ctx, uuid)
+}
+
+func (mw instrumentingMiddleware) GetPeople(ctx context.Context, q titanic.PeopleQuery) (page titanic.PeoplePage, err error) {
+	defer func(begin time.Time) { mw.instrument("GetPeople", begin, err) }(time.Now())
+	return mw.next.GetPeople(ctx, q)
+}
+
+func (mw instrumentingMiddleware) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) (err error) {
+	defer func(begin time.Time) { mw.instrument("StreamPeople", begin, err) }(time.Now())
+	return mw.next.StreamPeople(ctx, q, fn)
+}
+
+func (mw instrumentingMiddleware) WatchPeople(ctx context.Context, lastEventID uint64, fn func(titanic.Event) error) (err error) {
+	defer func(begin time.Time) { mw.instrument("WatchPeople", begin, err) }(time.Now())
+	return mw.next.WatchPeople(ctx, lastEventID, fn)
+}
+
+func (mw instrumentingMiddleware) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (stats titanic.Statistics, err error) {
+	defer func(begin time.Time) { mw.instrument("GetStatistics", begin, err) }(time.Now())
+	return mw.next.GetStatistics(ctx, q)
+}
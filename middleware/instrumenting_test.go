@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/events"
+	"gitlab.com/hyperd/titanic/ratelimit"
+)
+
+func TestErrorLabel(t *testing.T) {
+	invalid := &titanic.ValidationError{Fields: []titanic.FieldError{{Field: "name"}}}
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{titanic.ErrNotFound, "not_found"},
+		{titanic.ErrMalformedRequest.WithDetail("invalid JSON"), "malformed_request"},
+		{fmt.Errorf("get: %w", titanic.ErrTimeout), "timeout"},
+		{invalid, "validation_failed"},
+		{fmt.Errorf("import: %w", invalid), "validation_failed"},
+		{ratelimit.ErrLimited, "rate_limited"},
+		{events.ErrOverflow, "event_overflow"},
+		{errors.New("connection refused"), "internal"},
+	} {
+		if got := errorLabel(tc.err); got != tc.want {
+			t.Errorf("errorLabel(%v): got %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+// labelCounter records the label values of the counts.
+type labelCounter struct {
+	labels *[][]string
+	values []string
+}
+
+func (c labelCounter) With(labelValues ...string) metrics.Counter {
+	return labelCounter{c.labels, append(append([]string{}, c.values...), labelValues...)}
+}
+
+func (c labelCounter) Add(float64) { *c.labels = append(*c.labels, c.values) }
+
+func TestInstrumentWrappedError(t *testing.T) {
+	var labels [][]string
+	mw := instrumentingMiddleware{
+		requestCount:   discard.NewCounter(),
+		errorCount:     labelCounter{labels: &labels},
+		requestLatency: discard.NewHistogram(),
+	}
+	mw.instrument("PatchPeople", time.Now(), titanic.ErrMalformedRequest.WithDetail("invalid JSON"))
+
+	want := [][]string{{"method", "PatchPeople", "error", "malformed_request"}}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels: got %v, want %v", labels, want)
+	}
+}
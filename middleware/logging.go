@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -35,6 +36,13 @@ func (mw loggingMiddleware) PostPeople(ctx context.Context, p titanic.People) (i
 	return mw.next.PostPeople(ctx, p)
 }
 
+func (mw loggingMiddleware) PostPeopleBatch(ctx context.Context, people []titanic.People) (ids []string, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log("method", "PostPeopleBatch", "count", len(people), "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return mw.next.PostPeopleBatch(ctx, people)
+}
+
 func (mw loggingMiddleware) GetPeopleByID(ctx context.Context, uuid uuid.UUID) (p titanic.People, err error) {
 	defer func(begin time.Time) {
 		mw.logger.Log("method", "GetPeopleByID", "uuid", uuid, "took", time.Since(begin), "err", err)
@@ -56,16 +64,51 @@ func (mw loggingMiddleware) PatchPeople(ctx context.Context, uuid uuid.UUID, p t
 	return mw.next.PatchPeople(ctx, uuid, p)
 }
 
-func (mw loggingMiddleware) DeletePeople(ctx context.Context, uuid uuid.UUID) (id string, err error) {
+func (mw loggingMiddleware) DeletePeople(ctx context.Context, uuid uuid.UUID, version int) (id string, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log("method", "DeletePeople", "uuid", uuid, "version", version, "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return mw.next.DeletePeople(ctx, uuid, version)
+}
+
+func (mw loggingMiddleware) RestorePeople(ctx context.Context, uuid uuid.UUID) (err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log("method", "RestorePeople", "uuid", uuid, "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return mw.next.RestorePeople(ctx, uuid)
+}
+
+func (mw loggingMiddleware) GetPeopleHistory(ctx context.Context, uuid uuid.UUID) (history []titanic.Change, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log("method", "GetPeopleHistory", "uuid", uuid, "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return mw.next.GetPeopleHistory(ctx, uuid)
+}
+
+func (mw loggingMiddleware) GetPeople(ctx context.Context, q titanic.PeopleQuery) (page titanic.PeoplePage, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log("method", "GetPeople", "limit", q.Limit, "offset", q.Offset, "cursor", q.Cursor, "sort", titanic.FormatSort(q.Sort), "include_deleted", q.IncludeDeleted, "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return mw.next.GetPeople(ctx, q)
+}
+
+func (mw loggingMiddleware) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) (err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log("method", "StreamPeople", "sort", titanic.FormatSort(q.Sort), "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return mw.next.StreamPeople(ctx, q, fn)
+}
+
+func (mw loggingMiddleware) WatchPeople(ctx context.Context, lastEventID uint64, fn func(titanic.Event) error) (err error) {
 	defer func(begin time.Time) {
-		mw.logger.Log("method", "DeletePeople", "uuid", uuid, "took", time.Since(begin), "err", err)
+		mw.logger.Log("method", "WatchPeople", "last_event_id", lastEventID, "took", time.Since(begin), "err", err)
 	}(time.Now())
-	return mw.next.DeletePeople(ctx, uuid)
+	return mw.next.WatchPeople(ctx, lastEventID, fn)
 }
 
-func (mw loggingMiddleware) GetPeople(ctx context.Context) (allPeople []titanic.People, err error) {
+func (mw loggingMiddleware) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (stats titanic.Statistics, err error) {
 	defer func(begin time.Time) {
-		mw.logger.Log("method", "GetPeople", "took", time.Since(begin), "err", err)
+		mw.logger.Log("method", "GetStatistics", "group_by", strings.Join(q.GroupBy, ","), "took", time.Since(begin), "err", err)
 	}(time.Now())
-	return mw.next.GetPeople(ctx)
+	return mw.next.GetStatistics(ctx, q)
 }
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/tracing"
+)
+
+// TracingMiddleware provides a Middleware tracing each method in a child of
+// the span carried by the context, which the next service, and in turn the
+// repository, receive.
+func TracingMiddleware() Middleware {
+	return func(next titanic.Service) titanic.Service {
+		return &tracingMiddleware{
+			next: next,
+		}
+	}
+}
+
+type tracingMiddleware struct {
+	next titanic.Service
+}
+
+func (mw tracingMiddleware) PostPeople(ctx context.Context, p titanic.People) (id string, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.PostPeople")
+	defer func() { span.Finish(err) }()
+	return mw.next.PostPeople(ctx, p)
+}
+
+func (mw tracingMiddleware) PostPeopleBatch(ctx context.Context, people []titanic.People) (ids []string, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.PostPeopleBatch", "count", len(people))
+	defer func() { span.Finish(err) }()
+	return mw.next.PostPeopleBatch(ctx, people)
+}
+
+func (mw tracingMiddleware) GetPeopleByID(ctx context.Context, uuid uuid.UUID) (p titanic.People, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.GetPeopleByID", "uuid", uuid.String())
+	defer func() { span.Finish(err) }()
+	return mw.next.GetPeopleByID(ctx, uuid)
+}
+
+func (mw tracingMiddleware) PutPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.PutPeople", "uuid", uuid.String())
+	defer func() { span.Finish(err) }()
+	return mw.next.PutPeople(ctx, uuid, p)
+}
+
+func (mw tracingMiddleware) PatchPeople(ctx context.Context, uuid uuid.UUID, p titanic.People) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.PatchPeople", "uuid", uuid.String())
+	defer func() { span.Finish(err) }()
+	return mw.next.PatchPeople(ctx, uuid, p)
+}
+
+func (mw tracingMiddleware) DeletePeople(ctx context.Context, uuid uuid.UUID, version int) (id string, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.DeletePeople", "uuid", uuid.String(), "version", version)
+	defer func() { span.Finish(err) }()
+	return mw.next.DeletePeople(ctx, uuid, version)
+}
+
+func (mw tracingMiddleware) RestorePeople(ctx context.Context, uuid uuid.UUID) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.RestorePeople", "uuid", uuid.String())
+	defer func() { span.Finish(err) }()
+	return mw.next.RestorePeople(ctx, uuid)
+}
+
+func (mw tracingMiddleware) GetPeopleHistory(ctx context.Context, uuid uuid.UUID) (history []titanic.Change, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.GetPeopleHistory", "uuid", uuid.String())
+	defer func() { span.Finish(err) }()
+	return mw.next.GetPeopleHistory(ctx, uuid)
+}
+
+func (mw tracingMiddleware) GetPeople(ctx context.Context, q titanic.PeopleQuery) (page titanic.PeoplePage, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.GetPeople", "limit", q.Limit, "offset", q.Offset, "sort", titanic.FormatSort(q.Sort))
+	defer func() { span.Finish(err) }()
+	return mw.next.GetPeople(ctx, q)
+}
+
+func (mw tracingMiddleware) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.StreamPeople", "sort", titanic.FormatSort(q.Sort))
+	defer func() { span.Finish(err) }()
+	return mw.next.StreamPeople(ctx, q, fn)
+}
+
+func (mw tracingMiddleware) WatchPeople(ctx context.Context, lastEventID uint64, fn func(titanic.Event) error) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.WatchPeople", "last_event_id", lastEventID)
+	defer func() { span.Finish(err) }()
+	return mw.next.WatchPeople(ctx, lastEventID, fn)
+}
+
+func (mw tracingMiddleware) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (stats titanic.Statistics, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Service.GetStatistics")
+	defer func() { span.Finish(err) }()
+	return mw.next.GetStatistics(ctx, q)
+}
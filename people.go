@@ -2,30 +2,56 @@ package titanic
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// People represents a single passenger (People).
+// People represents a single passenger (People). The rules of its fields
+// are checked by Validate.
 type People struct {
 	// gorm.Model
 	ID                    uuid.UUID `json:"uuid,omitempty" gorm:"primary_key"`
 	Survived              *bool     `json:"survived,omitempty"`
-	Pclass                *int      `json:"pclass,omitempty" valid:"numeric"`
-	Name                  string    `json:"name,omitempty" valid:"alphanum,stringlength(2|70)"` // https://webarchive.nationalarchives.gov.uk/20100407173424/http://www.cabinetoffice.gov.uk/govtalk/schemasstandards/e-gif/datastandards.aspx
-	Sex                   string    `json:"sex,omitempty" valid:"in(male|female|not declared)"`
-	Age                   *int      `json:"age,omitempty" valid:"numeric,range(0|116)"`
-	SiblingsSpousesAbroad *int      `json:"siblings_spouses_abroad,omitempty" valid:"numeric,range(0|20)"`
-	ParentsChildrenAboard *int      `json:"parents_children_aboard,omitempty" valid:"numeric,range(0|20)"`
-	Fare                  *float32  `json:"fare,omitempty" valid:"float"`
+	Pclass                *int      `json:"pclass,omitempty"`
+	Name                  string    `json:"name,omitempty"` // https://webarchive.nationalarchives.gov.uk/20100407173424/http://www.cabinetoffice.gov.uk/govtalk/schemasstandards/e-gif/datastandards.aspx
+	Sex                   string    `json:"sex,omitempty"`
+	Age                   *int      `json:"age,omitempty"`
+	SiblingsSpousesAbroad *int      `json:"siblings_spouses_abroad,omitempty"`
+	ParentsChildrenAboard *int      `json:"parents_children_aboard,omitempty"`
+	Fare                  *float32  `json:"fare,omitempty"`
+	// Version starts at 1 and is incremented by each update. When set on an
+	// update, the update only applies to that version of the passenger, and
+	// fails with ErrVersionConflict otherwise. AnyVersion only requires the
+	// passenger to exist.
+	Version   int       `json:"version,omitempty" gorm:"not null;default:1"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is set when the passenger is deleted. Deleted passengers are
+	// left out of the listings, unless asked for, and can be restored until
+	// they are purged.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" sql:"index"`
 }
 
+// AnyVersion is the version of an update that matches every version of the
+// passenger, but fails with ErrVersionConflict if it is missing, as
+// If-Match: * does.
+const AnyVersion = -1
+
 // Repository describes the persistence on people model
 type Repository interface {
 	PostPeople(ctx context.Context, p People) (string, error)
+	PostPeopleBatch(ctx context.Context, people []People) ([]string, error)
 	GetPeopleByID(ctx context.Context, ID uuid.UUID) (People, error)
-	PutPeople(ctx context.Context, ID uuid.UUID, p People) error
+	// PutPeople reports whether the passenger was created, rather than
+	// updated.
+	PutPeople(ctx context.Context, ID uuid.UUID, p People) (created bool, err error)
 	PatchPeople(ctx context.Context, ID uuid.UUID, p People) error
-	DeletePeople(ctx context.Context, ID uuid.UUID) (string, error)
-	GetPeople(ctx context.Context) ([]People, error)
+	DeletePeople(ctx context.Context, ID uuid.UUID, version int) (string, error)
+	RestorePeople(ctx context.Context, ID uuid.UUID) error
+	PurgePeople(ctx context.Context, before time.Time) (int, error)
+	GetPeopleHistory(ctx context.Context, ID uuid.UUID) ([]Change, error)
+	GetPeople(ctx context.Context, q PeopleQuery) (PeoplePage, error)
+	StreamPeople(ctx context.Context, q PeopleQuery, fn func(People) error) error
+	GetStatistics(ctx context.Context, q StatisticsQuery) (Statistics, error)
 }
@@ -0,0 +1,250 @@
+package titanic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Page size boundaries applied to people listings.
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// PeopleQuery describes which slice of the people collection to list.
+// Offset and Cursor are mutually exclusive: the first one skips a fixed
+// number of passengers, the second one resumes right after the passenger
+// returned last by a previous page. Streams ignore the pagination, and go
+// through every passenger matching the filter. Deleted passengers are only
+// listed along with the others when IncludeDeleted is set.
+type PeopleQuery struct {
+	Limit          int
+	Offset         int
+	Cursor         string
+	Filter         PeopleFilter
+	Sort           []SortKey
+	IncludeDeleted bool
+}
+
+// PeopleFilter narrows a people listing down to the passengers matching all
+// of the given criteria. Nil or empty fields are not applied; the ranges are
+// inclusive, and passengers with no value for a filtered field never match.
+type PeopleFilter struct {
+	Survived *bool    `json:"survived,omitempty"`
+	Pclass   *int     `json:"pclass,omitempty"`
+	Sex      string   `json:"sex,omitempty"`
+	AgeMin   *int     `json:"age_min,omitempty"`
+	AgeMax   *int     `json:"age_max,omitempty"`
+	FareMin  *float32 `json:"fare_min,omitempty"`
+	FareMax  *float32 `json:"fare_max,omitempty"`
+}
+
+// Validate checks that the filter ranges are consistent.
+func (f PeopleFilter) Validate() error {
+	if f.AgeMin != nil && f.AgeMax != nil && *f.AgeMin > *f.AgeMax {
+		return ErrInvalidQuery
+	}
+	if f.FareMin != nil && f.FareMax != nil && *f.FareMin > *f.FareMax {
+		return ErrInvalidQuery
+	}
+	return nil
+}
+
+// Validate checks the query boundaries and applies the default page size.
+func (q *PeopleQuery) Validate() error {
+	if q.Limit < 0 || q.Limit > MaxPageSize || q.Offset < 0 {
+		return ErrInvalidQuery
+	}
+	if q.Offset > 0 && q.Cursor != "" {
+		return ErrInvalidQuery
+	}
+	if err := q.Filter.Validate(); err != nil {
+		return err
+	}
+	if q.Cursor != "" {
+		if _, err := q.DecodeCursor(); err != nil {
+			return err
+		}
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+	return nil
+}
+
+// DecodeCursor decodes the query cursor, and checks that it was issued for
+// the same ordering.
+func (q PeopleQuery) DecodeCursor() (Cursor, error) {
+	c, err := ParseCursor(q.Cursor)
+	if err != nil {
+		return c, err
+	}
+	if c.Sort != FormatSort(q.Sort) || len(c.Values) != len(q.Sort) {
+		return c, ErrInvalidQuery
+	}
+	for i, k := range q.Sort {
+		if !k.valid(c.Values[i]) {
+			return c, ErrInvalidQuery
+		}
+	}
+	return c, nil
+}
+
+// PeoplePage is a single page of a people listing.
+type PeoplePage struct {
+	People     []People `json:"people"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	Total      int      `json:"total"`
+}
+
+// Cursor marks the position of the last passenger returned in a page: its
+// ID, and its values for each of the sort keys of the listing.
+// Clients handle it as an opaque token.
+type Cursor struct {
+	ID     uuid.UUID     `json:"id"`
+	Sort   string        `json:"sort,omitempty"`
+	Values []interface{} `json:"values,omitempty"`
+}
+
+// NewCursor returns the cursor pointing right after the given passenger in a
+// listing ordered by the given sort keys.
+func NewCursor(p People, sort []SortKey) Cursor {
+	c := Cursor{ID: p.ID, Sort: FormatSort(sort)}
+	for _, k := range sort {
+		c.Values = append(c.Values, k.Value(p))
+	}
+	return c
+}
+
+// String encodes the cursor into its opaque representation.
+func (c Cursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseCursor decodes an opaque cursor token.
+func ParseCursor(s string) (Cursor, error) {
+	var c Cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidQuery
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, ErrInvalidQuery
+	}
+	return c, nil
+}
+
+// sortColumns maps the sortable fields, named after their JSON attribute, to
+// their column.
+var sortColumns = map[string]string{
+	"survived":                "survived",
+	"pclass":                  "pclass",
+	"name":                    "name",
+	"sex":                     "sex",
+	"age":                     "age",
+	"siblings_spouses_abroad": "siblings_spouses_abroad",
+	"parents_children_aboard": "parents_children_aboard",
+	"fare":                    "fare",
+}
+
+// SortKey orders a people listing by a single field. Listings are always
+// ordered by ID last, to break the ties deterministically, and passengers with
+// no value for a field sort before any other passenger in ascending order.
+type SortKey struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+// ParseSort parses a comma separated list of fields, each one optionally
+// prefixed by '-' for descending order, e.g. "-fare,name".
+func ParseSort(s string) ([]SortKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var keys []SortKey
+	seen := map[string]bool{}
+	for _, f := range strings.Split(s, ",") {
+		k := SortKey{Field: strings.TrimSpace(f)}
+		if strings.HasPrefix(k.Field, "-") {
+			k.Field, k.Desc = k.Field[1:], true
+		}
+		if _, ok := sortColumns[k.Field]; !ok || seen[k.Field] {
+			return nil, ErrInvalidQuery
+		}
+		seen[k.Field] = true
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// FormatSort is the inverse of ParseSort.
+func FormatSort(keys []SortKey) string {
+	fields := make([]string, len(keys))
+	for i, k := range keys {
+		fields[i] = k.Field
+		if k.Desc {
+			fields[i] = "-" + k.Field
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// Column returns the column backing the sort key field.
+func (k SortKey) Column() string {
+	return sortColumns[k.Field]
+}
+
+// Value returns the value of the sort key field for the given passenger:
+// nil when missing, otherwise a bool, a float64 or a string, so that it
+// survives the encoding of a cursor unchanged.
+func (k SortKey) Value(p People) interface{} {
+	switch k.Field {
+	case "survived":
+		if p.Survived != nil {
+			return *p.Survived
+		}
+	case "pclass":
+		return intValue(p.Pclass)
+	case "name":
+		return p.Name
+	case "sex":
+		return p.Sex
+	case "age":
+		return intValue(p.Age)
+	case "siblings_spouses_abroad":
+		return intValue(p.SiblingsSpousesAbroad)
+	case "parents_children_aboard":
+		return intValue(p.ParentsChildrenAboard)
+	case "fare":
+		if p.Fare != nil {
+			return float64(*p.Fare)
+		}
+	}
+	return nil
+}
+
+// valid reports whether v may be a value of the sort key field.
+func (k SortKey) valid(v interface{}) bool {
+	switch v.(type) {
+	case nil:
+		return true
+	case bool:
+		return k.Field == "survived"
+	case string:
+		return k.Field == "name" || k.Field == "sex"
+	case float64:
+		return k.Field != "survived" && k.Field != "name" && k.Field != "sex"
+	}
+	return false
+}
+
+func intValue(i *int) interface{} {
+	if i == nil {
+		return nil
+	}
+	return float64(*i)
+}
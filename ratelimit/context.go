@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+
+	"gitlab.com/hyperd/titanic/auth"
+)
+
+type client struct {
+	addr     string
+	quota    Quota
+	recorded bool
+}
+
+type clientKey struct{}
+
+// NewContext returns a copy of ctx carrying the address of the client, which
+// identifies the anonymous clients, and recording the quota the client is
+// left with once its request is limited.
+func NewContext(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, clientKey{}, &client{addr: addr})
+}
+
+// QuotaFromContext returns the quota recorded in ctx, if any.
+func QuotaFromContext(ctx context.Context) (Quota, bool) {
+	c, ok := ctx.Value(clientKey{}).(*client)
+	if !ok || !c.recorded {
+		return Quota{}, false
+	}
+	return c.quota, true
+}
+
+// keyOf returns the key of the bucket of the client: the subject of its
+// credentials, i.e. its API key or the subject of its JWT, or else its
+// address.
+func keyOf(ctx context.Context) string {
+	if claims, err := auth.FromContext(ctx); err == nil && claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+	if c, ok := ctx.Value(clientKey{}).(*client); ok {
+		return "addr:" + c.addr
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Proxies are the proxies trusted to report the address of the clients in
+// X-Forwarded-For, e.g. the ingress.
+type Proxies []*net.IPNet
+
+// ParseProxies parses a comma separated list of addresses and CIDR ranges,
+// e.g. "10.0.0.0/8,192.168.1.10".
+func ParseProxies(s string) (Proxies, error) {
+	var proxies Proxies
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy %q", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q", p)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// trusts reports whether the address is one of a trusted proxy.
+func (p Proxies) trusts(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientAddr returns the address of the client of a request coming from
+// remoteAddr, with the given X-Forwarded-For header. The header is only
+// honoured when the request comes from a trusted proxy: the address is then
+// the last one of the header not of a trusted proxy, as the previous ones
+// are set by the client, and can't be trusted.
+func (p Proxies) ClientAddr(remoteAddr, forwardedFor string) string {
+	addr := remoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	if forwardedFor == "" || !p.trusts(addr) {
+		return addr
+	}
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		addr = hop
+		if !p.trusts(hop) {
+			break
+		}
+	}
+	return addr
+}
@@ -0,0 +1,194 @@
+// Package ratelimit limits the rate of the requests of each client with
+// token buckets: every client, identified by the subject of its credentials
+// or else by its address, has a bucket refilled at a steady rate, and each
+// request takes from it the tokens its method costs.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"gitlab.com/hyperd/titanic"
+)
+
+// CodeRateLimited is the code of ErrLimited.
+const CodeRateLimited titanic.Code = "rate_limited"
+
+// ErrLimited is returned when the client exhausted its bucket.
+var ErrLimited = titanic.NewError(CodeRateLimited, "rate limit exceeded")
+
+// DefaultCosts are the costs of the methods, in tokens: reads are cheaper
+// than writes, and the bulk methods the most expensive.
+var DefaultCosts = map[string]int{
+	"GetPeopleByID":    1,
+	"GetPeopleHistory": 1,
+	"GetPeople":        1,
+	"GetStatistics":    2,
+	"WatchPeople":      2,
+	"ExportPeople":     10,
+	"PostPeople":       5,
+	"PutPeople":        5,
+	"PatchPeople":      5,
+	"DeletePeople":     5,
+	"RestorePeople":    5,
+	"PostPeopleBatch":  20,
+	"ImportPeople":     50,
+}
+
+// sweepInterval is the interval at which the buckets of the idle clients,
+// which are full again, are dropped.
+const sweepInterval = time.Minute
+
+// Limiter limits the rate of the requests of each client.
+type Limiter struct {
+	rate    float64
+	burst   int
+	costs   map[string]int
+	proxies Proxies
+
+	mtx       sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a Limiter refilling the buckets of the clients with
+// rate tokens per second, up to burst tokens. The methods cost the tokens
+// given by costs, or 1 when missing. The clients behind the given proxies
+// are identified by the address the proxies report.
+func NewLimiter(rate float64, burst int, costs map[string]int, proxies Proxies) *Limiter {
+	return &Limiter{
+		rate:      rate,
+		burst:     burst,
+		costs:     costs,
+		proxies:   proxies,
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// ClientAddr returns the address of the client of a request, as resolved by
+// the trusted proxies of the limiter: see Proxies.ClientAddr.
+func (l *Limiter) ClientAddr(remoteAddr, forwardedFor string) string {
+	return l.proxies.ClientAddr(remoteAddr, forwardedFor)
+}
+
+// Quota is the state of the bucket of a client, as left by its last request.
+type Quota struct {
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full
+	RetryAfter time.Duration // until the request can be retried, once limited
+}
+
+// take takes the tokens from the bucket of the client, unless it doesn't
+// hold enough of them.
+func (l *Limiter) take(client string, cost int) (Quota, bool) {
+	if cost > l.burst {
+		cost = l.burst
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	allowed := b.tokens >= float64(cost)
+	q := Quota{Limit: l.burst}
+	if allowed {
+		b.tokens -= float64(cost)
+	} else {
+		q.RetryAfter = l.duration(float64(cost) - b.tokens)
+	}
+	q.Remaining = int(b.tokens)
+	q.Reset = l.duration(float64(l.burst) - b.tokens)
+	return q, allowed
+}
+
+// sweep drops the buckets which are full again.
+func (l *Limiter) sweep(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= float64(l.burst) {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// duration returns the time it takes to refill the tokens.
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.rate * float64(time.Second)))
+}
+
+// Middleware returns an endpoint middleware limiting the rate of the
+// requests to the method, failing them with ErrLimited once the client
+// exhausted its bucket. The quota of the client is recorded in the context,
+// if it was attached with NewContext.
+func (l *Limiter) Middleware(method string) endpoint.Middleware {
+	cost, ok := l.costs[method]
+	if !ok {
+		cost = 1
+	}
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			q, allowed := l.take(keyOf(ctx), cost)
+			if c, ok := ctx.Value(clientKey{}).(*client); ok {
+				c.quota, c.recorded = q, true
+			}
+			if !allowed {
+				return nil, ErrLimited
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// ParseCosts returns the default costs, overridden by a comma separated list
+// of method=cost pairs, e.g. "GetPeople=2,ExportPeople=20".
+func ParseCosts(s string) (map[string]int, error) {
+	costs := make(map[string]int, len(DefaultCosts))
+	for method, cost := range DefaultCosts {
+		costs[method] = cost
+	}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid cost %q", pair)
+		}
+		method := strings.TrimSpace(kv[0])
+		if _, ok := DefaultCosts[method]; !ok {
+			return nil, fmt.Errorf("unknown method %q", method)
+		}
+		cost, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || cost < 0 {
+			return nil, fmt.Errorf("invalid cost %q", pair)
+		}
+		costs[method] = cost
+	}
+	return costs, nil
+}
@@ -2,26 +2,45 @@ package titanic
 
 import (
 	"context"
-	"errors"
 
 	"github.com/google/uuid"
 )
 
 // Response errors
 var (
-	ErrInconsistentIDs = errors.New("inconsistent IDs")
-	ErrAlreadyExists   = errors.New("already exists")
-	ErrNotFound        = errors.New("not found")
-	ErrCmdRepository   = errors.New("unable to command repository")
-	ErrQueryRepository = errors.New("unable to query repository")
+	ErrInconsistentIDs = NewError(CodeInconsistentIDs, "inconsistent IDs")
+	ErrAlreadyExists   = NewError(CodeAlreadyExists, "already exists")
+	ErrNotFound        = NewError(CodeNotFound, "not found")
+	ErrCmdRepository   = NewError(CodeRepositoryWrite, "unable to command repository")
+	ErrQueryRepository = NewError(CodeRepositoryRead, "unable to query repository")
+	ErrInvalidQuery    = NewError(CodeInvalidQuery, "invalid query")
+	ErrVersionConflict = NewError(CodeVersionConflict, "version conflict")
+	ErrUnauthenticated = NewError(CodeUnauthenticated, "unauthenticated")
+	ErrForbidden       = NewError(CodeForbidden, "forbidden")
+	// ErrMalformedRequest is returned, along with a detail, when a request
+	// can't be decoded.
+	ErrMalformedRequest = NewError(CodeMalformedRequest, "malformed request")
+	// ErrCanceled and ErrTimeout are returned when a request is abandoned
+	// because its context was canceled, e.g. by the client disconnecting,
+	// or because it didn't complete in time.
+	ErrCanceled = NewError(CodeCanceled, "request canceled")
+	ErrTimeout  = NewError(CodeTimeout, "request timed out")
 )
 
 // Service is a CRUD interface for People in the Titanic collection.
 type Service interface {
 	PostPeople(ctx context.Context, p People) (string, error)
+	PostPeopleBatch(ctx context.Context, people []People) ([]string, error)
 	GetPeopleByID(ctx context.Context, ID uuid.UUID) (People, error)
 	PutPeople(ctx context.Context, ID uuid.UUID, p People) error
 	PatchPeople(ctx context.Context, ID uuid.UUID, p People) error
-	DeletePeople(ctx context.Context, ID uuid.UUID) (string, error)
-	GetPeople(ctx context.Context) ([]People, error)
+	DeletePeople(ctx context.Context, ID uuid.UUID, version int) (string, error)
+	RestorePeople(ctx context.Context, ID uuid.UUID) error
+	GetPeopleHistory(ctx context.Context, ID uuid.UUID) ([]Change, error)
+	GetPeople(ctx context.Context, q PeopleQuery) (PeoplePage, error)
+	StreamPeople(ctx context.Context, q PeopleQuery, fn func(People) error) error
+	// WatchPeople calls fn with the events published after lastEventID,
+	// as the passengers change, until ctx is done or fn fails.
+	WatchPeople(ctx context.Context, lastEventID uint64, fn func(Event) error) error
+	GetStatistics(ctx context.Context, q StatisticsQuery) (Statistics, error)
 }
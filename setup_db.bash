@@ -32,9 +32,22 @@ initdb() {
     	siblings_spouses_abroad INT8 NULL,
     	parents_children_aboard INT8 NULL,
     	fare DECIMAL NULL,
+    	version INT8 NOT NULL DEFAULT 1,
 		CONSTRAINT \"primary\" PRIMARY KEY (id ASC),
     	INDEX idx_peoples_deleted_at (deleted_at ASC),
-    	FAMILY \"primary\" (created_at, updated_at, deleted_at, id, survived, pclass, name, sex, age, siblings_spouses_abroad, parents_children_aboard, fare)
+    	FAMILY \"primary\" (created_at, updated_at, deleted_at, id, survived, pclass, name, sex, age, siblings_spouses_abroad, parents_children_aboard, fare, version)
+    );"
+    $SQL -d titanic -e "CREATE TABLE people_audit (
+    	id UUID NOT NULL,
+    	people_id UUID NULL,
+    	action STRING NULL,
+    	actor STRING NULL,
+    	request_id STRING NULL,
+    	at TIMESTAMPTZ NULL,
+    	before STRING NULL,
+    	after STRING NULL,
+    	CONSTRAINT \"primary\" PRIMARY KEY (id ASC),
+    	INDEX idx_people_audit_people_id (people_id ASC)
     );"
 }
 
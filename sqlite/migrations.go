@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// migrations are the changes of the schema, applied in order, once each:
+// the schema version, kept in the user_version of the database, is the
+// number of migrations applied. A released migration must never change;
+// the schema is changed by appending a migration.
+var migrations = []string{
+	// 1: the passengers, and the history of their changes.
+	`CREATE TABLE people (
+		id TEXT PRIMARY KEY,
+		survived BOOLEAN,
+		pclass INTEGER,
+		name TEXT,
+		sex TEXT,
+		age INTEGER,
+		siblings_spouses_abroad INTEGER,
+		parents_children_aboard INTEGER,
+		fare REAL,
+		version INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME,
+		updated_at DATETIME,
+		deleted_at DATETIME
+	);
+	CREATE INDEX idx_people_deleted_at ON people (deleted_at);
+	CREATE TABLE people_audit (
+		id TEXT PRIMARY KEY,
+		people_id TEXT,
+		action TEXT,
+		actor TEXT,
+		request_id TEXT,
+		at DATETIME,
+		"before" TEXT,
+		"after" TEXT
+	);
+	CREATE INDEX idx_people_audit_people_id ON people_audit (people_id);`,
+}
+
+// migrate applies the migrations missing from the database, each in a
+// transaction of its own, along with the bump of the schema version.
+func migrate(db *gorm.DB) error {
+	for {
+		var version int
+		if err := db.Raw("PRAGMA user_version").Row().Scan(&version); err != nil {
+			return err
+		}
+		if version > len(migrations) {
+			return fmt.Errorf("schema version %d is newer than the %d migrations known", version, len(migrations))
+		}
+		if version == len(migrations) {
+			return nil
+		}
+
+		tx := db.Begin()
+		if tx.Error != nil {
+			return tx.Error
+		}
+		if err := tx.Exec(migrations[version]).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", version+1, err)
+		}
+		// PRAGMA doesn't take parameters.
+		if err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", version+1, err)
+		}
+		if err := tx.Commit().Error; err != nil {
+			return fmt.Errorf("migration %d: %v", version+1, err)
+		}
+	}
+}
@@ -0,0 +1,486 @@
+// Package sqlite implements the titanic.Repository on a SQLite database
+// file, for the single-node deployments and the tests needing the
+// passengers to survive restarts without running CockroachDB.
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite" // registers the sqlite3 driver
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/gormstore"
+	"gitlab.com/hyperd/titanic/tracing"
+)
+
+type repository struct {
+	db     *gorm.DB
+	logger log.Logger
+
+	// mu serializes the write transactions, which SQLite runs one at a
+	// time: a transaction reading before writing would otherwise fail to
+	// upgrade its lock while another one writes.
+	mu sync.Mutex
+}
+
+// Open opens the SQLite database file at path, created if missing, in
+// write-ahead logging mode, so that the reads don't wait for the writes.
+// The statements wait up to 5 seconds for the database to be unlocked by the
+// other processes, e.g. titanic import.
+func Open(path string) (*gorm.DB, error) {
+	params := url.Values{
+		"_busy_timeout": {"5000"},
+		"_journal_mode": {"WAL"},
+	}
+	db, err := gorm.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	// Disable table name's pluralization, as for CockroachDB.
+	db.SingularTable(true)
+	return db, nil
+}
+
+// New returns a concrete repository backed by the SQLite database opened
+// by Open, migrating its schema to the latest version first. It has the
+// semantics of the CockroachDB repository: the passengers are soft deleted,
+// the changes are recorded in the people_audit table, and the statements of
+// each call are canceled once its context is done. The times are stored in
+// UTC, so that they sort as the strings SQLite stores them as.
+func New(db *gorm.DB, logger log.Logger) (titanic.Repository, error) {
+	if err := migrate(db); err != nil {
+		return nil, fmt.Errorf("sqlite: %v", err)
+	}
+
+	return &repository{
+		db:     db,
+		logger: log.With(logger, "rep", "sqlite"),
+	}, nil
+}
+
+// Functions of type txnFunc are run by runTransaction and runQuery in a
+// transaction.
+type txnFunc func(*gorm.DB) error
+
+// runTransaction runs fn in a write transaction bound to ctx, committed if
+// fn succeeds and rolled back otherwise.
+func (repo *repository) runTransaction(ctx context.Context, fn txnFunc) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return runQuery(ctx, repo.db, fn)
+}
+
+// runQuery runs fn in a transaction bound to ctx, so that its statements are
+// interrupted along with ctx, and read from the same snapshot.
+func runQuery(ctx context.Context, db *gorm.DB, fn txnFunc) error {
+	tx := db.BeginTx(ctx, nil)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// startSpan starts the span of a repository method, as a child of the span
+// carried by ctx, returning the function ending it, given the error of the
+// method, which it returns as ErrCanceled or ErrTimeout if the method failed
+// because ctx was done.
+func startSpan(ctx context.Context, method string) func(error) error {
+	_, span := tracing.StartSpan(ctx, "sqlite."+method, "db.system", "sqlite")
+	return func(err error) error {
+		err = contextError(ctx, err)
+		span.Finish(err)
+		return err
+	}
+}
+
+func contextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch ctx.Err() {
+	case context.Canceled:
+		return titanic.ErrCanceled
+	case context.DeadlineExceeded:
+		return titanic.ErrTimeout
+	}
+	return err
+}
+
+// now returns the current time, in UTC.
+func now() time.Time {
+	return gorm.NowFunc().UTC()
+}
+
+func (repo *repository) PostPeople(ctx context.Context, people titanic.People) (_ string, err error) {
+	done := startSpan(ctx, "PostPeople")
+	defer func() { err = done(err) }()
+
+	id := uuid.New()
+	err = repo.runTransaction(ctx, func(tx *gorm.DB) error {
+		return create(ctx, tx, titanic.ActionCreate, id, people)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (repo *repository) PostPeopleBatch(ctx context.Context, people []titanic.People) (_ []string, err error) {
+	done := startSpan(ctx, "PostPeopleBatch")
+	defer func() { err = done(err) }()
+
+	ids := make([]string, len(people))
+
+	// The whole batch is written in a single transaction.
+	err = repo.runTransaction(ctx, func(tx *gorm.DB) error {
+		for i, p := range people {
+			id := uuid.New()
+			if err := create(ctx, tx, titanic.ActionCreate, id, p); err != nil {
+				return err
+			}
+			ids[i] = id.String()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// create creates the passenger with the given ID, at version 1, and records
+// its creation as the given action.
+func create(ctx context.Context, tx *gorm.DB, action string, id uuid.UUID, people titanic.People) error {
+	created := people
+	created.ID = id
+	created.Version = 1
+	created.CreatedAt = now()
+	created.UpdatedAt = created.CreatedAt
+	created.DeletedAt = nil
+
+	if err := tx.Create(&created).Error; err != nil {
+		return err
+	}
+	return gormstore.RecordChange(ctx, tx, action, id, nil, &created)
+}
+
+func (repo *repository) GetPeopleByID(ctx context.Context, id uuid.UUID) (_ titanic.People, err error) {
+	done := startSpan(ctx, "GetPeopleByID")
+	defer func() { err = done(err) }()
+
+	var people = titanic.People{}
+
+	err = runQuery(ctx, repo.db, func(tx *gorm.DB) error {
+		return tx.Where("id = ?", id).First(&people).Error
+	})
+	if gorm.IsRecordNotFoundError(err) {
+		return people, titanic.ErrNotFound
+	}
+
+	return people, err
+}
+
+func (repo *repository) PutPeople(ctx context.Context, id uuid.UUID, people titanic.People) (created bool, err error) {
+	done := startSpan(ctx, "PutPeople")
+	defer func() { err = done(err) }()
+
+	err = repo.runTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := gormstore.Snapshot(tx, id)
+		if err != nil {
+			return err
+		}
+
+		bumped, err := bumpVersion(tx, id, people.Version)
+		if err != nil {
+			return err
+		}
+
+		created = !bumped
+		if created {
+			if people.Version != 0 {
+				return titanic.ErrVersionConflict // a missing passenger matches no version
+			}
+			if before != nil {
+				return titanic.ErrNotFound // a deleted passenger must be restored first
+			}
+			// PUT can create
+			return create(ctx, tx, titanic.ActionPut, id, people)
+		}
+
+		if err := update(tx, id, people); err != nil {
+			return err
+		}
+		return gormstore.RecordUpdate(ctx, tx, titanic.ActionPut, id, before)
+	})
+	return created, err
+}
+
+func (repo *repository) PatchPeople(ctx context.Context, id uuid.UUID, people titanic.People) (err error) {
+	done := startSpan(ctx, "PatchPeople")
+	defer func() { err = done(err) }()
+
+	return repo.runTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := gormstore.Snapshot(tx, id)
+		if err != nil {
+			return err
+		}
+
+		bumped, err := bumpVersion(tx, id, people.Version)
+		if err != nil {
+			return err
+		}
+		if !bumped {
+			if people.Version != 0 {
+				return titanic.ErrVersionConflict
+			}
+			return titanic.ErrNotFound // PATCH = update existing, don't create
+		}
+
+		if err := update(tx, id, people); err != nil {
+			return err
+		}
+		return gormstore.RecordUpdate(ctx, tx, titanic.ActionPatch, id, before)
+	})
+}
+
+// update sets the fields of the passenger which are set in people, leaving
+// the others alone, as gorm updates with a struct do.
+func update(tx *gorm.DB, id uuid.UUID, people titanic.People) error {
+	columns := map[string]interface{}{}
+	if people.Survived != nil {
+		columns["survived"] = *people.Survived
+	}
+	if people.Pclass != nil {
+		columns["pclass"] = *people.Pclass
+	}
+	if people.Name != "" {
+		columns["name"] = people.Name
+	}
+	if people.Sex != "" {
+		columns["sex"] = people.Sex
+	}
+	if people.Age != nil {
+		columns["age"] = *people.Age
+	}
+	if people.SiblingsSpousesAbroad != nil {
+		columns["siblings_spouses_abroad"] = *people.SiblingsSpousesAbroad
+	}
+	if people.ParentsChildrenAboard != nil {
+		columns["parents_children_aboard"] = *people.ParentsChildrenAboard
+	}
+	if people.Fare != nil {
+		columns["fare"] = *people.Fare
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	// UpdateColumns leaves updated_at alone, set in UTC by bumpVersion.
+	return tx.Model(&titanic.People{}).Where("id = ?", id).UpdateColumns(columns).Error
+}
+
+// bumpVersion increments the version of the passenger, provided it is at the
+// given version, if any, and reports whether it did.
+func bumpVersion(tx *gorm.DB, id uuid.UUID, version int) (bool, error) {
+	scope := tx.Model(&titanic.People{}).Where("id = ?", id)
+	if version > 0 {
+		scope = scope.Where("version = ?", version)
+	}
+
+	bumped := scope.UpdateColumns(map[string]interface{}{
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": now(),
+	})
+	return bumped.RowsAffected > 0, bumped.Error
+}
+
+func (repo *repository) DeletePeople(ctx context.Context, id uuid.UUID, version int) (_ string, err error) {
+	done := startSpan(ctx, "DeletePeople")
+	defer func() { err = done(err) }()
+
+	return id.String(), repo.runTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := gormstore.Snapshot(tx, id)
+		if err != nil {
+			return err
+		}
+
+		scope := tx.Model(&titanic.People{}).Where("id = ?", id)
+		if version > 0 {
+			scope = scope.Where("version = ?", version)
+		}
+
+		// The row is only marked as deleted, until purged, as a new version
+		// of the passenger: the versions read before no longer match.
+		deleted := scope.UpdateColumns(map[string]interface{}{
+			"deleted_at": now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		if deleted.RowsAffected == 0 {
+			if version != 0 {
+				return titanic.ErrVersionConflict
+			}
+			return titanic.ErrNotFound
+		}
+
+		return gormstore.RecordUpdate(ctx, tx, titanic.ActionDelete, id, before)
+	})
+}
+
+func (repo *repository) RestorePeople(ctx context.Context, id uuid.UUID) (err error) {
+	done := startSpan(ctx, "RestorePeople")
+	defer func() { err = done(err) }()
+
+	return repo.runTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := gormstore.Snapshot(tx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return titanic.ErrNotFound
+		}
+		if before.DeletedAt == nil {
+			// Restoring a passenger that isn't deleted has no effect.
+			return nil
+		}
+
+		if err := tx.Unscoped().Model(&titanic.People{}).Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{
+				"deleted_at": gorm.Expr("NULL"),
+				"version":    gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+
+		return gormstore.RecordUpdate(ctx, tx, titanic.ActionRestore, id, before)
+	})
+}
+
+func (repo *repository) PurgePeople(ctx context.Context, before time.Time) (_ int, err error) {
+	done := startSpan(ctx, "PurgePeople")
+	defer func() { err = done(err) }()
+
+	// The history of the purged passengers is kept.
+	var n int
+	err = repo.runTransaction(ctx, func(tx *gorm.DB) error {
+		purged := tx.Unscoped().Where("deleted_at < ?", before.UTC()).Delete(&titanic.People{})
+		n = int(purged.RowsAffected)
+		return purged.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (repo *repository) GetPeopleHistory(ctx context.Context, id uuid.UUID) (_ []titanic.Change, err error) {
+	done := startSpan(ctx, "GetPeopleHistory")
+	defer func() { err = done(err) }()
+
+	var history []titanic.Change
+	err = runQuery(ctx, repo.db, func(tx *gorm.DB) (err error) {
+		history, err = gormstore.History(tx, id)
+		return err
+	})
+	return history, err
+}
+
+func (repo *repository) GetPeople(ctx context.Context, q titanic.PeopleQuery) (_ titanic.PeoplePage, err error) {
+	done := startSpan(ctx, "GetPeople")
+	defer func() { err = done(err) }()
+
+	var page = titanic.PeoplePage{People: []titanic.People{}}
+
+	// The count and the page are read from the same snapshot.
+	err = runQuery(ctx, repo.db, func(tx *gorm.DB) error {
+		if q.IncludeDeleted {
+			tx = tx.Unscoped()
+		}
+		filtered := gormstore.FilterPeople(tx, q.Filter)
+
+		if err := filtered.Model(&titanic.People{}).Count(&page.Total).Error; err != nil {
+			return err
+		}
+
+		// Fetch one extra row to find out whether there is a next page.
+		scope := gormstore.SortPeople(filtered, q.Sort).Limit(q.Limit + 1)
+
+		if q.Cursor != "" {
+			c, err := q.DecodeCursor()
+			if err != nil {
+				return err
+			}
+			scope = gormstore.AfterCursor(scope, c, q.Sort)
+		} else if q.Offset > 0 {
+			scope = scope.Offset(q.Offset)
+		}
+
+		return scope.Find(&page.People).Error
+	})
+	if err != nil {
+		return page, err
+	}
+
+	if len(page.People) > q.Limit {
+		page.People = page.People[:q.Limit]
+		page.NextCursor = titanic.NewCursor(page.People[q.Limit-1], q.Sort).String()
+	}
+
+	return page, nil
+}
+
+func (repo *repository) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) (err error) {
+	done := startSpan(ctx, "StreamPeople")
+	defer func() { err = done(err) }()
+
+	return runQuery(ctx, repo.db, func(tx *gorm.DB) error {
+		if q.IncludeDeleted {
+			tx = tx.Unscoped()
+		}
+
+		// Scan the rows one at a time, rather than loading the whole result set.
+		rows, err := gormstore.SortPeople(gormstore.FilterPeople(tx, q.Filter), q.Sort).Model(&titanic.People{}).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var people titanic.People
+			if err := tx.ScanRows(rows, &people); err != nil {
+				return err
+			}
+			if err := fn(people); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
+
+func (repo *repository) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (_ titanic.Statistics, err error) {
+	done := startSpan(ctx, "GetStatistics")
+	defer func() { err = done(err) }()
+
+	// The aggregates and the medians are read from the same snapshot.
+	var stats titanic.Statistics
+	err = runQuery(ctx, repo.db, func(tx *gorm.DB) (err error) {
+		stats, err = gormstore.Statistics(tx, q)
+		return err
+	})
+	return stats, err
+}
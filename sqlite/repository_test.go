@@ -0,0 +1,248 @@
+package sqlite
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/google/uuid"
+	"gitlab.com/hyperd/titanic"
+)
+
+// newTestRepository returns a repository backed by a database file of its
+// own, along with the function removing it.
+func newTestRepository(t *testing.T) (titanic.Repository, func()) {
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := Open(filepath.Join(dir, "titanic.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	repo, err := New(db, log.NewNopLogger())
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return repo, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestPutPeopleCreates(t *testing.T) {
+	repo, remove := newTestRepository(t)
+	defer remove()
+	ctx := context.Background()
+
+	id := uuid.New()
+	created, err := repo.PutPeople(ctx, id, titanic.People{ID: id, Name: "Amy", Sex: "female"})
+	if err != nil || !created {
+		t.Fatalf("PutPeople: got %v, %v, want a creation", created, err)
+	}
+	created, err = repo.PutPeople(ctx, id, titanic.People{ID: id, Name: "Bea", Sex: "female"})
+	if err != nil || created {
+		t.Fatalf("PutPeople: got %v, %v, want an update", created, err)
+	}
+
+	p, err := repo.GetPeopleByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetPeopleByID: %v", err)
+	}
+	if p.Name != "Bea" || p.Version != 2 {
+		t.Errorf("GetPeopleByID: got %q at version %d, want %q at version 2", p.Name, p.Version, "Bea")
+	}
+	history, err := repo.GetPeopleHistory(ctx, id)
+	if err != nil {
+		t.Fatalf("GetPeopleHistory: %v", err)
+	}
+	if len(history) != 2 || history[0].Before != nil || history[1].Before == nil || history[1].Before.Name != "Amy" {
+		t.Errorf("GetPeopleHistory: got %+v, want the creation and the update", history)
+	}
+}
+
+func TestPatchPeopleMissing(t *testing.T) {
+	repo, remove := newTestRepository(t)
+	defer remove()
+
+	id := uuid.New()
+	if err := repo.PatchPeople(context.Background(), id, titanic.People{ID: id, Name: "Amy"}); err != titanic.ErrNotFound {
+		t.Errorf("PatchPeople: got %v, want %v", err, titanic.ErrNotFound)
+	}
+	if _, err := repo.GetPeopleByID(context.Background(), id); err != titanic.ErrNotFound {
+		t.Errorf("GetPeopleByID: got %v, want %v", err, titanic.ErrNotFound)
+	}
+}
+
+func TestVersionConflict(t *testing.T) {
+	repo, remove := newTestRepository(t)
+	defer remove()
+	ctx := context.Background()
+
+	id, err := repo.PostPeople(ctx, titanic.People{ID: uuid.New(), Name: "Amy", Sex: "female"})
+	if err != nil {
+		t.Fatalf("PostPeople: %v", err)
+	}
+	uid := uuid.MustParse(id)
+
+	if err := repo.PatchPeople(ctx, uid, titanic.People{ID: uid, Name: "Bea", Version: 1}); err != nil {
+		t.Fatalf("PatchPeople: %v", err)
+	}
+	for _, tc := range []struct {
+		name   string
+		update func(version int) error
+	}{
+		{"PutPeople", func(version int) error {
+			_, err := repo.PutPeople(ctx, uid, titanic.People{ID: uid, Name: "Cid", Sex: "male", Version: version})
+			return err
+		}},
+		{"PatchPeople", func(version int) error {
+			return repo.PatchPeople(ctx, uid, titanic.People{ID: uid, Name: "Cid", Version: version})
+		}},
+		{"DeletePeople", func(version int) error {
+			_, err := repo.DeletePeople(ctx, uid, version)
+			return err
+		}},
+	} {
+		if err := tc.update(1); err != titanic.ErrVersionConflict {
+			t.Errorf("%s at a stale version: got %v, want %v", tc.name, err, titanic.ErrVersionConflict)
+		}
+	}
+
+	missing := uuid.New()
+	if _, err := repo.PutPeople(ctx, missing, titanic.People{ID: missing, Name: "Dan", Sex: "male", Version: titanic.AnyVersion}); err != titanic.ErrVersionConflict {
+		t.Errorf("PutPeople of a missing passenger at any version: got %v, want %v", err, titanic.ErrVersionConflict)
+	}
+
+	p, err := repo.GetPeopleByID(ctx, uid)
+	if err != nil {
+		t.Fatalf("GetPeopleByID: %v", err)
+	}
+	if p.Name != "Bea" || p.Version != 2 {
+		t.Errorf("GetPeopleByID: got %q at version %d, want %q at version 2", p.Name, p.Version, "Bea")
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	repo, remove := newTestRepository(t)
+	defer remove()
+	ctx := context.Background()
+
+	id, err := repo.PostPeople(ctx, titanic.People{ID: uuid.New(), Name: "Amy", Sex: "female"})
+	if err != nil {
+		t.Fatalf("PostPeople: %v", err)
+	}
+	uid := uuid.MustParse(id)
+
+	if _, err := repo.DeletePeople(ctx, uid, 1); err != nil {
+		t.Fatalf("DeletePeople: %v", err)
+	}
+	if _, err := repo.GetPeopleByID(ctx, uid); err != titanic.ErrNotFound {
+		t.Errorf("GetPeopleByID of a deleted passenger: got %v, want %v", err, titanic.ErrNotFound)
+	}
+	if _, err := repo.DeletePeople(ctx, uid, 0); err != titanic.ErrNotFound {
+		t.Errorf("DeletePeople of a deleted passenger: got %v, want %v", err, titanic.ErrNotFound)
+	}
+	if _, err := repo.PutPeople(ctx, uid, titanic.People{ID: uid, Name: "Bea", Sex: "female"}); err != titanic.ErrNotFound {
+		t.Errorf("PutPeople of a deleted passenger: got %v, want %v", err, titanic.ErrNotFound)
+	}
+
+	for _, tc := range []struct {
+		includeDeleted bool
+		want           int
+	}{
+		{false, 0},
+		{true, 1},
+	} {
+		page, err := repo.GetPeople(ctx, titanic.PeopleQuery{Limit: 10, IncludeDeleted: tc.includeDeleted})
+		if err != nil {
+			t.Fatalf("GetPeople: %v", err)
+		}
+		if page.Total != tc.want || len(page.People) != tc.want {
+			t.Errorf("GetPeople(include_deleted=%v): got %d of %d passengers, want %d", tc.includeDeleted, len(page.People), page.Total, tc.want)
+		}
+	}
+
+	if err := repo.RestorePeople(ctx, uid); err != nil {
+		t.Fatalf("RestorePeople: %v", err)
+	}
+	p, err := repo.GetPeopleByID(ctx, uid)
+	if err != nil {
+		t.Fatalf("GetPeopleByID of a restored passenger: %v", err)
+	}
+	// The deletion and the restoration are versions of their own.
+	if p.DeletedAt != nil || p.Version != 3 {
+		t.Errorf("GetPeopleByID: got deleted at %v, version %d, want not deleted, version 3", p.DeletedAt, p.Version)
+	}
+
+	// Restoring a passenger that isn't deleted has no effect.
+	if err := repo.RestorePeople(ctx, uid); err != nil {
+		t.Fatalf("RestorePeople of a live passenger: %v", err)
+	}
+	if p, err := repo.GetPeopleByID(ctx, uid); err != nil || p.Version != 3 {
+		t.Errorf("GetPeopleByID: got version %d, %v, want version 3", p.Version, err)
+	}
+	if err := repo.RestorePeople(ctx, uuid.New()); err != titanic.ErrNotFound {
+		t.Errorf("RestorePeople of a missing passenger: got %v, want %v", err, titanic.ErrNotFound)
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	repo, remove := newTestRepository(t)
+	defer remove()
+	ctx := context.Background()
+
+	// Several passengers share each class, and some have none, so that the
+	// pages break within runs of equal sort keys.
+	classes := []*int{intPtr(1), intPtr(3), nil, intPtr(1), intPtr(3), intPtr(3), nil, intPtr(1), intPtr(2)}
+	for _, pclass := range classes {
+		if _, err := repo.PostPeople(ctx, titanic.People{ID: uuid.New(), Name: "Amy", Sex: "female", Pclass: pclass}); err != nil {
+			t.Fatalf("PostPeople: %v", err)
+		}
+	}
+
+	for _, sort := range []string{"pclass", "-pclass", "pclass,-name", "-pclass,name"} {
+		keys, err := titanic.ParseSort(sort)
+		if err != nil {
+			t.Fatalf("ParseSort(%q): %v", sort, err)
+		}
+		all, err := repo.GetPeople(ctx, titanic.PeopleQuery{Limit: len(classes), Sort: keys})
+		if err != nil {
+			t.Fatalf("GetPeople: %v", err)
+		}
+
+		var paged []titanic.People
+		q := titanic.PeopleQuery{Limit: 2, Sort: keys}
+		for pages := 0; ; pages++ {
+			if pages > len(classes) {
+				t.Fatalf("sort=%s: the pages don't end", sort)
+			}
+			page, err := repo.GetPeople(ctx, q)
+			if err != nil {
+				t.Fatalf("GetPeople: %v", err)
+			}
+			paged = append(paged, page.People...)
+			if page.NextCursor == "" {
+				break
+			}
+			q.Cursor = page.NextCursor
+		}
+
+		if len(paged) != len(all.People) {
+			t.Fatalf("sort=%s: got %d passengers in pages, want %d", sort, len(paged), len(all.People))
+		}
+		for i := range paged {
+			if paged[i].ID != all.People[i].ID {
+				t.Errorf("sort=%s: passenger %d: got %v, want %v", sort, i, paged[i].ID, all.People[i].ID)
+			}
+		}
+	}
+}
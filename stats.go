@@ -0,0 +1,167 @@
+package titanic
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Fields the statistics can be grouped by.
+const (
+	GroupByPclass  = "pclass"
+	GroupBySex     = "sex"
+	GroupByAgeBand = "age_band"
+)
+
+// DefaultAgeBandWidth is the width, in years, of the age bands when not
+// configured otherwise.
+const DefaultAgeBandWidth = 10
+
+// StatisticsQuery describes how to aggregate the passengers matching Filter.
+type StatisticsQuery struct {
+	GroupBy      []string
+	AgeBandWidth int
+	Filter       PeopleFilter
+}
+
+// Validate checks the grouping fields and applies the default age band width.
+func (q *StatisticsQuery) Validate() error {
+	seen := map[string]bool{}
+	for _, g := range q.GroupBy {
+		switch g {
+		case GroupByPclass, GroupBySex, GroupByAgeBand:
+		default:
+			return ErrInvalidQuery
+		}
+		if seen[g] {
+			return ErrInvalidQuery
+		}
+		seen[g] = true
+	}
+	if q.AgeBandWidth < 0 || q.AgeBandWidth > 116 {
+		return ErrInvalidQuery
+	}
+	if q.AgeBandWidth == 0 {
+		q.AgeBandWidth = DefaultAgeBandWidth
+	}
+	return q.Filter.Validate()
+}
+
+// Grouped reports whether the statistics are grouped by the given field.
+func (q StatisticsQuery) Grouped(field string) bool {
+	for _, g := range q.GroupBy {
+		if g == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Statistics are the survival statistics of the passengers, one entry per
+// group. Without grouping, there is a single group made of every passenger.
+type Statistics struct {
+	GroupBy []string          `json:"group_by,omitempty"`
+	Groups  []StatisticsGroup `json:"groups"`
+}
+
+// AgeBand is an inclusive range of ages.
+type AgeBand struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// NewAgeBand returns the band of the given width holding age.
+func NewAgeBand(age, width int) AgeBand {
+	min := age - age%width
+	return AgeBand{Min: min, Max: min + width - 1}
+}
+
+func (b AgeBand) String() string {
+	return fmt.Sprintf("%d-%d", b.Min, b.Max)
+}
+
+// StatisticsGroup aggregates a group of passengers. The group fields are only
+// set when grouping by them, and missing when the passengers have no value
+// for them; the rates, means and medians are missing when there is no value
+// to aggregate.
+type StatisticsGroup struct {
+	Pclass  *int     `json:"pclass,omitempty"`
+	Sex     *string  `json:"sex,omitempty"`
+	AgeBand *AgeBand `json:"age_band,omitempty"`
+
+	Count        int      `json:"count"`
+	Survived     int      `json:"survived"`
+	SurvivalRate *float64 `json:"survival_rate,omitempty"`
+	MeanAge      *float64 `json:"mean_age,omitempty"`
+	MedianAge    *float64 `json:"median_age,omitempty"`
+	MeanFare     *float64 `json:"mean_fare,omitempty"`
+	MedianFare   *float64 `json:"median_fare,omitempty"`
+}
+
+// Key identifies the group among the others of the same statistics.
+func (g StatisticsGroup) Key() string {
+	key := ""
+	if g.Pclass != nil {
+		key += fmt.Sprintf("pclass=%d;", *g.Pclass)
+	}
+	if g.Sex != nil {
+		key += fmt.Sprintf("sex=%q;", *g.Sex)
+	}
+	if g.AgeBand != nil {
+		key += fmt.Sprintf("age_band=%s;", g.AgeBand)
+	}
+	return key
+}
+
+// SortGroups orders the groups by class, sex and age band, missing values
+// first, so that every repository returns them in the same order.
+func SortGroups(groups []StatisticsGroup) {
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if c := compareIntPtr(a.Pclass, b.Pclass); c != 0 {
+			return c < 0
+		}
+		if c := compareStringPtr(a.Sex, b.Sex); c != 0 {
+			return c < 0
+		}
+		var aMin, bMin *int
+		if a.AgeBand != nil {
+			aMin = &a.AgeBand.Min
+		}
+		if b.AgeBand != nil {
+			bMin = &b.AgeBand.Min
+		}
+		return compareIntPtr(aMin, bMin) < 0
+	})
+}
+
+func compareIntPtr(a, b *int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case *a < *b:
+		return -1
+	case *a > *b:
+		return 1
+	}
+	return 0
+}
+
+func compareStringPtr(a, b *string) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case *a < *b:
+		return -1
+	case *a > *b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// WriterExporter writes each span as a line of JSON, e.g. to os.Stdout for
+// local testing.
+type WriterExporter struct {
+	mtx sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterExporter returns an exporter writing the spans to w.
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{enc: json.NewEncoder(w)}
+}
+
+// ExportSpan implements Exporter.
+func (e *WriterExporter) ExportSpan(s SpanData) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.enc.Encode(s)
+}
+
+// InMemoryExporter keeps the spans in process, e.g. to inspect them in tests.
+type InMemoryExporter struct {
+	mtx   sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter returns an empty in-memory exporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan implements Exporter.
+func (e *InMemoryExporter) ExportSpan(s SpanData) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+// Spans returns the spans exported so far, in the order they ended.
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	spans := make([]SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Reset drops the spans exported so far.
+func (e *InMemoryExporter) Reset() {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.spans = nil
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader is the W3C Trace Context header carrying the parent span.
+const TraceparentHeader = "traceparent"
+
+// ErrInvalidTraceparent is returned when parsing a malformed traceparent.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// ParseTraceparent parses a W3C traceparent header value, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceparent(v string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 {
+		return sc, ErrInvalidTraceparent
+	}
+	version, err := decodeHex(parts[0], 1)
+	if err != nil || version[0] == 0xff {
+		return sc, ErrInvalidTraceparent
+	}
+	// Version 00 has exactly four fields, while later versions may append
+	// more of them.
+	if version[0] == 0 && len(parts) != 4 {
+		return sc, ErrInvalidTraceparent
+	}
+
+	traceID, err := decodeHex(parts[1], len(sc.TraceID))
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	copy(sc.TraceID[:], traceID)
+
+	spanID, err := decodeHex(parts[2], len(sc.SpanID))
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	copy(sc.SpanID[:], spanID)
+
+	flags, err := decodeHex(parts[3], 1)
+	if err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	sc.Sampled = flags[0]&0x01 == 0x01
+
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// Traceparent formats the span context as a W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	var flags byte
+	if sc.Sampled {
+		flags = 0x01
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+}
+
+// Extract returns the remote span context carried by the headers; it is
+// invalid when the headers carry none, or a malformed one.
+func Extract(h http.Header) SpanContext {
+	sc, _ := ParseTraceparent(h.Get(TraceparentHeader))
+	return sc
+}
+
+// Inject sets the traceparent header of an outgoing request to the span
+// carried by ctx, if any.
+func Inject(ctx context.Context, h http.Header) {
+	if sc := SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+		h.Set(TraceparentHeader, sc.Traceparent())
+	}
+}
+
+// decodeHex decodes a lowercase hex string of exactly n bytes.
+func decodeHex(s string, n int) ([]byte, error) {
+	if len(s) != 2*n || strings.ToLower(s) != s {
+		return nil, ErrInvalidTraceparent
+	}
+	return hex.DecodeString(s)
+}
@@ -0,0 +1,227 @@
+// Package tracing provides a minimal, OpenTelemetry-style distributed
+// tracing: spans are started by the transports, carried by the
+// context.Context down to the repositories, and handed to a pluggable
+// Exporter once ended. Traces are propagated across processes with the W3C
+// traceparent header.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid reports whether the trace ID is not made of zeroes only.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (t TraceID) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid reports whether the span ID is not made of zeroes only.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (s SpanID) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// SpanContext is the part of a span propagated to its children, locally and
+// across processes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both the trace and the span IDs are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanData is the record of an ended span, as handed to the exporters.
+type SpanData struct {
+	Name         string                 `json:"name"`
+	TraceID      TraceID                `json:"trace_id"`
+	SpanID       SpanID                 `json:"span_id"`
+	ParentSpanID *SpanID                `json:"parent_span_id,omitempty"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// Exporter receives the spans once they end. Implementations must be safe
+// for concurrent use.
+type Exporter interface {
+	ExportSpan(s SpanData)
+}
+
+// Tracer starts the root spans of the process, which export themselves, and
+// their children, through the tracer exporter.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer returns a Tracer exporting the spans through the given exporter.
+// A nil exporter drops the spans, while still propagating the traces.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// Start starts a span continuing the remote parent, when valid, or a new
+// trace otherwise. New traces are always sampled, while remote parents
+// decide whether their trace is.
+func (t *Tracer) Start(ctx context.Context, name string, remote SpanContext) (context.Context, *Span) {
+	s := &Span{
+		tracer: t,
+		name:   name,
+		start:  time.Now(),
+	}
+	if remote.IsValid() {
+		parent := remote.SpanID
+		s.parent = &parent
+		s.sc = SpanContext{TraceID: remote.TraceID, Sampled: remote.Sampled}
+	} else {
+		s.sc = SpanContext{TraceID: newTraceID(), Sampled: true}
+	}
+	s.sc.SpanID = newSpanID()
+	return ContextWithSpan(ctx, s), s
+}
+
+// Span is a single timed operation of a trace. The zero value, and a nil
+// Span, are valid no-op spans.
+type Span struct {
+	tracer *Tracer
+	name   string
+	sc     SpanContext
+	parent *SpanID
+	start  time.Time
+
+	mtx   sync.Mutex
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+// SpanContext returns the span context, to be propagated.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttributes records the given key value pairs on the span.
+func (s *Span) SetAttributes(keyvals ...interface{}) {
+	if s == nil || s.tracer == nil {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.attrs == nil {
+		s.attrs = map[string]interface{}{}
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if k, ok := keyvals[i].(string); ok {
+			s.attrs[k] = keyvals[i+1]
+		}
+	}
+}
+
+// SetError records the error the operation failed with, if any.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mtx.Lock()
+	s.err = err
+	s.mtx.Unlock()
+}
+
+// End ends the span, exporting it when sampled. Only the first call has
+// any effect.
+func (s *Span) End() {
+	if s == nil || s.tracer == nil {
+		return
+	}
+	s.mtx.Lock()
+	if s.ended {
+		s.mtx.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Name:         s.name,
+		TraceID:      s.sc.TraceID,
+		SpanID:       s.sc.SpanID,
+		ParentSpanID: s.parent,
+		Start:        s.start,
+		End:          time.Now(),
+		Attributes:   s.attrs,
+	}
+	if s.err != nil {
+		data.Error = s.err.Error()
+	}
+	s.mtx.Unlock()
+
+	if s.sc.Sampled && s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(data)
+	}
+}
+
+// Finish records err, if any, and ends the span. It is meant to be deferred
+// by functions with a named error result.
+func (s *Span) Finish(err error) {
+	s.SetError(err)
+	s.End()
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying the span.
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// StartSpan starts a child of the span carried by ctx, exported by the same
+// tracer. Without a span in ctx, the operation isn't traced: the returned
+// span is a no-op, and ctx is returned unchanged.
+func StartSpan(ctx context.Context, name string, keyvals ...interface{}) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil || parent.tracer == nil {
+		return ctx, nil
+	}
+	ctx, s := parent.tracer.Start(ctx, name, parent.sc)
+	s.SetAttributes(keyvals...)
+	return ctx, s
+}
+
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
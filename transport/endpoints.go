@@ -2,21 +2,31 @@ package transport
 
 import (
 	"context"
+	"io"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
 	"github.com/google/uuid"
 	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/importer"
 )
 
 // Endpoints collects all of the endpoints that compose a People titanic.People.
 type Endpoints struct {
-	PostPeopleEndpoint    endpoint.Endpoint
-	GetPeopleByIDEndpoint endpoint.Endpoint
-	PutPeopleEndpoint     endpoint.Endpoint
-	PatchPeopleEndpoint   endpoint.Endpoint
-	DeletePeopleEndpoint  endpoint.Endpoint
-	GetPeopleEndpoint     endpoint.Endpoint
-	GetAPIStatusEndpoint  endpoint.Endpoint
+	PostPeopleEndpoint       endpoint.Endpoint
+	PostPeopleBatchEndpoint  endpoint.Endpoint
+	ImportPeopleEndpoint     endpoint.Endpoint
+	GetPeopleByIDEndpoint    endpoint.Endpoint
+	PutPeopleEndpoint        endpoint.Endpoint
+	PatchPeopleEndpoint      endpoint.Endpoint
+	DeletePeopleEndpoint     endpoint.Endpoint
+	RestorePeopleEndpoint    endpoint.Endpoint
+	GetPeopleHistoryEndpoint endpoint.Endpoint
+	GetPeopleEndpoint        endpoint.Endpoint
+	ExportPeopleEndpoint     endpoint.Endpoint
+	WatchPeopleEndpoint      endpoint.Endpoint
+	GetStatisticsEndpoint    endpoint.Endpoint
+	GetAPIStatusEndpoint     endpoint.Endpoint
 }
 
 // MakeServerEndpoints returns an Endpoints struct where each endpoint invokes
@@ -24,16 +34,176 @@ type Endpoints struct {
 // server.
 func MakeServerEndpoints(s titanic.Service) Endpoints {
 	return Endpoints{
-		PostPeopleEndpoint:    MakePostPeopleEndpoint(s),
-		GetPeopleByIDEndpoint: MakeGetPeopleByIDEndpoint(s),
-		PutPeopleEndpoint:     MakePutPeopleEndpoint(s),
-		PatchPeopleEndpoint:   MakePatchPeopleEndpoint(s),
-		DeletePeopleEndpoint:  MakeDeletePeopleEndpoint(s),
-		GetPeopleEndpoint:     MakeGetPeopleEndpoint(s),
-		GetAPIStatusEndpoint:  MakeGetAPIStatusEndpoint(),
+		PostPeopleEndpoint:       MakePostPeopleEndpoint(s),
+		PostPeopleBatchEndpoint:  MakePostPeopleBatchEndpoint(s),
+		ImportPeopleEndpoint:     MakeImportPeopleEndpoint(importer.New(s, importer.DefaultBatchSize, log.NewNopLogger())),
+		GetPeopleByIDEndpoint:    MakeGetPeopleByIDEndpoint(s),
+		PutPeopleEndpoint:        MakePutPeopleEndpoint(s),
+		PatchPeopleEndpoint:      MakePatchPeopleEndpoint(s),
+		DeletePeopleEndpoint:     MakeDeletePeopleEndpoint(s),
+		RestorePeopleEndpoint:    MakeRestorePeopleEndpoint(s),
+		GetPeopleHistoryEndpoint: MakeGetPeopleHistoryEndpoint(s),
+		GetPeopleEndpoint:        MakeGetPeopleEndpoint(s),
+		ExportPeopleEndpoint:     MakeExportPeopleEndpoint(s),
+		WatchPeopleEndpoint:      MakeWatchPeopleEndpoint(s),
+		GetStatisticsEndpoint:    MakeGetStatisticsEndpoint(s),
+		GetAPIStatusEndpoint:     MakeGetAPIStatusEndpoint(),
 	}
 }
 
+// PostPeople implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) PostPeople(ctx context.Context, p titanic.People) (string, error) {
+	request := PostPeopleRequest{People: p}
+	response, err := e.PostPeopleEndpoint(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	resp := response.(PostPeopleResponse)
+	return resp.ID, resp.Err
+}
+
+// PostPeopleBatch implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) PostPeopleBatch(ctx context.Context, people []titanic.People) ([]string, error) {
+	request := PostPeopleBatchRequest{People: people}
+	response, err := e.PostPeopleBatchEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	resp := response.(PostPeopleBatchResponse)
+	return resp.IDs, resp.Err
+}
+
+// GetPeopleByID implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) GetPeopleByID(ctx context.Context, id uuid.UUID) (titanic.People, error) {
+	request := GetPeopleByIDRequest{ID: id}
+	response, err := e.GetPeopleByIDEndpoint(ctx, request)
+	if err != nil {
+		return titanic.People{}, err
+	}
+	resp := response.(GetPeopleByIDResponse)
+	return resp.People, resp.Err
+}
+
+// PutPeople implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) PutPeople(ctx context.Context, id uuid.UUID, p titanic.People) error {
+	request := PutPeopleRequest{ID: id, People: p}
+	response, err := e.PutPeopleEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	resp := response.(PutPeopleResponse)
+	return resp.Err
+}
+
+// PatchPeople implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) PatchPeople(ctx context.Context, id uuid.UUID, p titanic.People) error {
+	request := PatchPeopleRequest{ID: id, People: p}
+	response, err := e.PatchPeopleEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	resp := response.(PatchPeopleResponse)
+	return resp.Err
+}
+
+// DeletePeople implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) DeletePeople(ctx context.Context, id uuid.UUID, version int) (string, error) {
+	request := DeletePeopleRequest{ID: id, Version: version}
+	response, err := e.DeletePeopleEndpoint(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	resp := response.(DeletePeopleResponse)
+	return resp.ID, resp.Err
+}
+
+// RestorePeople implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) RestorePeople(ctx context.Context, id uuid.UUID) error {
+	request := RestorePeopleRequest{ID: id}
+	response, err := e.RestorePeopleEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	resp := response.(RestorePeopleResponse)
+	return resp.Err
+}
+
+// GetPeopleHistory implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) GetPeopleHistory(ctx context.Context, id uuid.UUID) ([]titanic.Change, error) {
+	request := GetPeopleHistoryRequest{ID: id}
+	response, err := e.GetPeopleHistoryEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	resp := response.(GetPeopleHistoryResponse)
+	return resp.History, resp.Err
+}
+
+// GetPeople implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) GetPeople(ctx context.Context, q titanic.PeopleQuery) (titanic.PeoplePage, error) {
+	request := GetPeopleRequest{
+		Limit:          q.Limit,
+		Offset:         q.Offset,
+		Cursor:         q.Cursor,
+		Filter:         q.Filter,
+		Sort:           q.Sort,
+		IncludeDeleted: q.IncludeDeleted,
+	}
+	response, err := e.GetPeopleEndpoint(ctx, request)
+	if err != nil {
+		return titanic.PeoplePage{}, err
+	}
+	resp := response.(GetPeopleResponse)
+	return titanic.PeoplePage{
+		People:     resp.People,
+		NextCursor: resp.NextCursor,
+		Total:      resp.Total,
+	}, resp.Err
+}
+
+// StreamPeople implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) StreamPeople(ctx context.Context, q titanic.PeopleQuery, fn func(titanic.People) error) error {
+	request := ExportPeopleRequest{Filter: q.Filter, Sort: q.Sort, IncludeDeleted: q.IncludeDeleted}
+	response, err := e.ExportPeopleEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	resp := response.(ExportPeopleResponse)
+	if resp.Err != nil {
+		return resp.Err
+	}
+	return resp.Stream(fn)
+}
+
+// WatchPeople implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) WatchPeople(ctx context.Context, lastEventID uint64, fn func(titanic.Event) error) error {
+	request := WatchPeopleRequest{LastEventID: lastEventID}
+	response, err := e.WatchPeopleEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	resp := response.(WatchPeopleResponse)
+	if resp.Err != nil {
+		return resp.Err
+	}
+	return resp.Stream(fn)
+}
+
+// GetStatistics implements titanic.Service. Primarily useful in a client.
+func (e Endpoints) GetStatistics(ctx context.Context, q titanic.StatisticsQuery) (titanic.Statistics, error) {
+	request := GetStatisticsRequest{
+		GroupBy:      q.GroupBy,
+		AgeBandWidth: q.AgeBandWidth,
+		Filter:       q.Filter,
+	}
+	response, err := e.GetStatisticsEndpoint(ctx, request)
+	if err != nil {
+		return titanic.Statistics{}, err
+	}
+	resp := response.(GetStatisticsResponse)
+	return resp.Statistics, resp.Err
+}
+
 // MakePostPeopleEndpoint returns an endpoint via the passed service.
 // Primarily useful in a server.
 func MakePostPeopleEndpoint(s titanic.Service) endpoint.Endpoint {
@@ -44,6 +214,26 @@ func MakePostPeopleEndpoint(s titanic.Service) endpoint.Endpoint {
 	}
 }
 
+// MakePostPeopleBatchEndpoint returns an endpoint via the passed service.
+// Primarily useful in a server.
+func MakePostPeopleBatchEndpoint(s titanic.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(PostPeopleBatchRequest)
+		ids, e := s.PostPeopleBatch(ctx, req.People)
+		return PostPeopleBatchResponse{IDs: ids, Err: e}, nil
+	}
+}
+
+// MakeImportPeopleEndpoint returns an endpoint via the passed importer.
+// Primarily useful in a server.
+func MakeImportPeopleEndpoint(i *importer.Importer) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ImportPeopleRequest)
+		report, e := i.Import(ctx, req.CSV)
+		return ImportPeopleResponse{Report: report, Err: e}, nil
+	}
+}
+
 // MakeGetPeopleByIDEndpoint returns an endpoint via the passed service.
 // Primarily useful in a server.
 func MakeGetPeopleByIDEndpoint(s titanic.Service) endpoint.Endpoint {
@@ -79,18 +269,94 @@ func MakePatchPeopleEndpoint(s titanic.Service) endpoint.Endpoint {
 func MakeDeletePeopleEndpoint(s titanic.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 		req := request.(DeletePeopleRequest)
-		id, e := s.DeletePeople(ctx, req.ID)
+		id, e := s.DeletePeople(ctx, req.ID, req.Version)
 		return DeletePeopleResponse{ID: id, Err: e}, nil
 	}
 }
 
+// MakeRestorePeopleEndpoint returns an endpoint via the passed service.
+// Primarily useful in a server.
+func MakeRestorePeopleEndpoint(s titanic.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(RestorePeopleRequest)
+		e := s.RestorePeople(ctx, req.ID)
+		return RestorePeopleResponse{Err: e}, nil
+	}
+}
+
+// MakeGetPeopleHistoryEndpoint returns an endpoint via the passed service.
+// Primarily useful in a server.
+func MakeGetPeopleHistoryEndpoint(s titanic.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(GetPeopleHistoryRequest)
+		history, e := s.GetPeopleHistory(ctx, req.ID)
+		return GetPeopleHistoryResponse{History: history, Err: e}, nil
+	}
+}
+
 // MakeGetPeopleEndpoint returns an endpoint via the passed service.
 // Primarily useful in a server.
 func MakeGetPeopleEndpoint(s titanic.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		// req := request.(GetPeopleResponse)
-		a, e := s.GetPeople(ctx)
-		return GetPeopleResponse{People: a, Err: e}, nil
+		req := request.(GetPeopleRequest)
+		page, e := s.GetPeople(ctx, titanic.PeopleQuery{
+			Limit:          req.Limit,
+			Offset:         req.Offset,
+			Cursor:         req.Cursor,
+			Filter:         req.Filter,
+			Sort:           req.Sort,
+			IncludeDeleted: req.IncludeDeleted,
+		})
+		return GetPeopleResponse{
+			People:     page.People,
+			NextCursor: page.NextCursor,
+			Total:      page.Total,
+			Err:        e,
+		}, nil
+	}
+}
+
+// MakeExportPeopleEndpoint returns an endpoint via the passed service.
+// Primarily useful in a server. The passengers are not read by the endpoint
+// itself, but streamed while the response is encoded.
+func MakeExportPeopleEndpoint(s titanic.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ExportPeopleRequest)
+		q := titanic.PeopleQuery{Filter: req.Filter, Sort: req.Sort, IncludeDeleted: req.IncludeDeleted}
+		return ExportPeopleResponse{
+			Format: req.Format,
+			Stream: func(fn func(titanic.People) error) error {
+				return s.StreamPeople(ctx, q, fn)
+			},
+		}, nil
+	}
+}
+
+// MakeWatchPeopleEndpoint returns an endpoint via the passed service.
+// Primarily useful in a server. The events are not watched by the endpoint
+// itself, but streamed while the response is encoded.
+func MakeWatchPeopleEndpoint(s titanic.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(WatchPeopleRequest)
+		return WatchPeopleResponse{
+			Stream: func(fn func(titanic.Event) error) error {
+				return s.WatchPeople(ctx, req.LastEventID, fn)
+			},
+		}, nil
+	}
+}
+
+// MakeGetStatisticsEndpoint returns an endpoint via the passed service.
+// Primarily useful in a server.
+func MakeGetStatisticsEndpoint(s titanic.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(GetStatisticsRequest)
+		stats, e := s.GetStatistics(ctx, titanic.StatisticsQuery{
+			GroupBy:      req.GroupBy,
+			AgeBandWidth: req.AgeBandWidth,
+			Filter:       req.Filter,
+		})
+		return GetStatisticsResponse{Statistics: stats, Err: e}, nil
 	}
 }
 
@@ -129,7 +395,36 @@ type PostPeopleResponse struct {
 	Err error  `json:"err,omitempty"`
 }
 
-func (r PostPeopleResponse) error() error { return r.Err }
+// Failed implements endpoint.Failer.
+func (r PostPeopleResponse) Failed() error { return r.Err }
+
+// PostPeopleBatchRequest request object
+type PostPeopleBatchRequest struct {
+	People []titanic.People `json:"people,omitempty"`
+}
+
+// PostPeopleBatchResponse response object
+type PostPeopleBatchResponse struct {
+	IDs []string `json:"ids,omitempty"`
+	Err error    `json:"err,omitempty"`
+}
+
+// Failed implements endpoint.Failer.
+func (r PostPeopleBatchResponse) Failed() error { return r.Err }
+
+// ImportPeopleRequest request object
+type ImportPeopleRequest struct {
+	CSV io.Reader `json:"-"`
+}
+
+// ImportPeopleResponse response object
+type ImportPeopleResponse struct {
+	importer.Report
+	Err error `json:"err,omitempty"`
+}
+
+// Failed implements endpoint.Failer.
+func (r ImportPeopleResponse) Failed() error { return r.Err }
 
 // GetPeopleByIDRequest request object
 type GetPeopleByIDRequest struct {
@@ -142,7 +437,8 @@ type GetPeopleByIDResponse struct {
 	Err    error          `json:"err,omitempty"`
 }
 
-func (r GetPeopleByIDResponse) error() error { return r.Err }
+// Failed implements endpoint.Failer.
+func (r GetPeopleByIDResponse) Failed() error { return r.Err }
 
 // PutPeopleRequest request object
 type PutPeopleRequest struct {
@@ -155,7 +451,8 @@ type PutPeopleResponse struct {
 	Err error `json:"err,omitempty"`
 }
 
-func (r PutPeopleResponse) error() error { return nil }
+// Failed implements endpoint.Failer.
+func (r PutPeopleResponse) Failed() error { return r.Err }
 
 // PatchPeopleRequest request object
 type PatchPeopleRequest struct {
@@ -168,11 +465,13 @@ type PatchPeopleResponse struct {
 	Err error `json:"err,omitempty"`
 }
 
-func (r PatchPeopleResponse) error() error { return r.Err }
+// Failed implements endpoint.Failer.
+func (r PatchPeopleResponse) Failed() error { return r.Err }
 
 // DeletePeopleRequest request object
 type DeletePeopleRequest struct {
-	ID uuid.UUID
+	ID      uuid.UUID
+	Version int
 }
 
 // DeletePeopleResponse response object
@@ -181,18 +480,104 @@ type DeletePeopleResponse struct {
 	Err error  `json:"err,omitempty"`
 }
 
-func (r DeletePeopleResponse) error() error { return r.Err }
+// Failed implements endpoint.Failer.
+func (r DeletePeopleResponse) Failed() error { return r.Err }
+
+// RestorePeopleRequest request object
+type RestorePeopleRequest struct {
+	ID uuid.UUID
+}
+
+// RestorePeopleResponse response object
+type RestorePeopleResponse struct {
+	Err error `json:"err,omitempty"`
+}
+
+// Failed implements endpoint.Failer.
+func (r RestorePeopleResponse) Failed() error { return r.Err }
 
-// GetPeopleRequest struct
-type GetPeopleRequest struct{}
+// GetPeopleHistoryRequest request object
+type GetPeopleHistoryRequest struct {
+	ID uuid.UUID
+}
+
+// GetPeopleHistoryResponse response object
+type GetPeopleHistoryResponse struct {
+	History []titanic.Change `json:"history,omitempty"`
+	Err     error            `json:"err,omitempty"`
+}
+
+// Failed implements endpoint.Failer.
+func (r GetPeopleHistoryResponse) Failed() error { return r.Err }
+
+// GetPeopleRequest request object
+type GetPeopleRequest struct {
+	Limit          int                  `json:"limit,omitempty"`
+	Offset         int                  `json:"offset,omitempty"`
+	Cursor         string               `json:"cursor,omitempty"`
+	Filter         titanic.PeopleFilter `json:"filter,omitempty"`
+	Sort           []titanic.SortKey    `json:"sort,omitempty"`
+	IncludeDeleted bool                 `json:"include_deleted,omitempty"`
+}
 
 // GetPeopleResponse response object
 type GetPeopleResponse struct {
-	People []titanic.People `json:"people,omitempty"`
-	Err    error            `json:"err,omitempty"`
+	People     []titanic.People `json:"people,omitempty"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	Total      int              `json:"total"`
+	Err        error            `json:"err,omitempty"`
+}
+
+// Failed implements endpoint.Failer.
+func (r GetPeopleResponse) Failed() error { return r.Err }
+
+// ExportPeopleRequest request object
+type ExportPeopleRequest struct {
+	Format         string               `json:"format,omitempty"`
+	Filter         titanic.PeopleFilter `json:"filter,omitempty"`
+	Sort           []titanic.SortKey    `json:"sort,omitempty"`
+	IncludeDeleted bool                 `json:"include_deleted,omitempty"`
+}
+
+// ExportPeopleResponse response object
+type ExportPeopleResponse struct {
+	Format string                                    `json:"format,omitempty"`
+	Stream func(fn func(titanic.People) error) error `json:"-"`
+	Err    error                                     `json:"err,omitempty"`
+}
+
+// Failed implements endpoint.Failer.
+func (r ExportPeopleResponse) Failed() error { return r.Err }
+
+// WatchPeopleRequest request object
+type WatchPeopleRequest struct {
+	LastEventID uint64 `json:"last_event_id,omitempty"`
+}
+
+// WatchPeopleResponse response object
+type WatchPeopleResponse struct {
+	Stream func(fn func(titanic.Event) error) error `json:"-"`
+	Err    error                                    `json:"err,omitempty"`
+}
+
+// Failed implements endpoint.Failer.
+func (r WatchPeopleResponse) Failed() error { return r.Err }
+
+// GetStatisticsRequest request object
+type GetStatisticsRequest struct {
+	GroupBy      []string             `json:"group_by,omitempty"`
+	AgeBandWidth int                  `json:"age_band,omitempty"`
+	Filter       titanic.PeopleFilter `json:"filter,omitempty"`
+}
+
+// GetStatisticsResponse response object
+type GetStatisticsResponse struct {
+	titanic.Statistics
+	Err error `json:"err,omitempty"`
 }
 
-func (r GetPeopleResponse) error() error { return r.Err }
+// Failed implements endpoint.Failer.
+func (r GetStatisticsResponse) Failed() error { return r.Err }
 
 // GetAPIStatusRequest request object
 type GetAPIStatusRequest struct{}
@@ -203,4 +588,5 @@ type GetAPIStatusResponse struct {
 	Err    error  `json:"err,omitempty"`
 }
 
-func (r GetAPIStatusResponse) error() error { return r.Err }
+// Failed implements endpoint.Failer.
+func (r GetAPIStatusResponse) Failed() error { return r.Err }
@@ -0,0 +1,19 @@
+module gitlab.com/hyperd/titanic/transport/grpc
+
+go 1.13
+
+require (
+	github.com/go-kit/kit v0.9.0
+	github.com/golang/protobuf v1.5.0
+	github.com/google/uuid v1.1.2
+	gitlab.com/hyperd/titanic v0.0.0-00010101000000-000000000000
+	gitlab.com/hyperd/titanic/transport v0.0.0-00010101000000-000000000000
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
+	google.golang.org/grpc v1.40.0
+	google.golang.org/protobuf v1.31.0
+)
+
+replace (
+	gitlab.com/hyperd/titanic => ../..
+	gitlab.com/hyperd/titanic/transport => ../../transport
+)
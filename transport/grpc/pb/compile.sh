@@ -0,0 +1,17 @@
+#!/usr/bin/env sh
+
+# Install proto3 from source
+#  brew install autoconf automake libtool
+#  git clone https://github.com/google/protobuf
+#  ./autogen.sh ; ./configure ; make ; make install
+#
+# Update the protoc Go and gRPC plugins via
+#  go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.31.0
+#  go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.3.0
+#
+# See also
+#  https://grpc.io/docs/languages/go/quickstart/
+
+protoc titanic.proto \
+	--go_out=paths=source_relative:. \
+	--go-grpc_out=paths=source_relative:.
@@ -0,0 +1,1381 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: titanic.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// People represents a single passenger. The wrapped fields are missing when
+// the passenger has no value for them.
+type People struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid                  string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Survived              *wrapperspb.BoolValue  `protobuf:"bytes,2,opt,name=survived,proto3" json:"survived,omitempty"`
+	Pclass                *wrapperspb.Int32Value `protobuf:"bytes,3,opt,name=pclass,proto3" json:"pclass,omitempty"`
+	Name                  string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Sex                   string                 `protobuf:"bytes,5,opt,name=sex,proto3" json:"sex,omitempty"`
+	Age                   *wrapperspb.Int32Value `protobuf:"bytes,6,opt,name=age,proto3" json:"age,omitempty"`
+	SiblingsSpousesAbroad *wrapperspb.Int32Value `protobuf:"bytes,7,opt,name=siblings_spouses_abroad,json=siblingsSpousesAbroad,proto3" json:"siblings_spouses_abroad,omitempty"`
+	ParentsChildrenAboard *wrapperspb.Int32Value `protobuf:"bytes,8,opt,name=parents_children_aboard,json=parentsChildrenAboard,proto3" json:"parents_children_aboard,omitempty"`
+	Fare                  *wrapperspb.FloatValue `protobuf:"bytes,9,opt,name=fare,proto3" json:"fare,omitempty"`
+	// The version of the passenger. Set on PutPeople or PatchPeople, the
+	// update fails with FailedPrecondition unless the passenger is at that
+	// version.
+	Version int32 `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *People) Reset() {
+	*x = People{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *People) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*People) ProtoMessage() {}
+
+func (x *People) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use People.ProtoReflect.Descriptor instead.
+func (*People) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *People) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *People) GetSurvived() *wrapperspb.BoolValue {
+	if x != nil {
+		return x.Survived
+	}
+	return nil
+}
+
+func (x *People) GetPclass() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.Pclass
+	}
+	return nil
+}
+
+func (x *People) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *People) GetSex() string {
+	if x != nil {
+		return x.Sex
+	}
+	return ""
+}
+
+func (x *People) GetAge() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.Age
+	}
+	return nil
+}
+
+func (x *People) GetSiblingsSpousesAbroad() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.SiblingsSpousesAbroad
+	}
+	return nil
+}
+
+func (x *People) GetParentsChildrenAboard() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.ParentsChildrenAboard
+	}
+	return nil
+}
+
+func (x *People) GetFare() *wrapperspb.FloatValue {
+	if x != nil {
+		return x.Fare
+	}
+	return nil
+}
+
+func (x *People) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// PeopleFilter narrows a listing down to the passengers matching all of the
+// set criteria. The ranges are inclusive.
+type PeopleFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Survived *wrapperspb.BoolValue  `protobuf:"bytes,1,opt,name=survived,proto3" json:"survived,omitempty"`
+	Pclass   *wrapperspb.Int32Value `protobuf:"bytes,2,opt,name=pclass,proto3" json:"pclass,omitempty"`
+	Sex      string                 `protobuf:"bytes,3,opt,name=sex,proto3" json:"sex,omitempty"`
+	AgeMin   *wrapperspb.Int32Value `protobuf:"bytes,4,opt,name=age_min,json=ageMin,proto3" json:"age_min,omitempty"`
+	AgeMax   *wrapperspb.Int32Value `protobuf:"bytes,5,opt,name=age_max,json=ageMax,proto3" json:"age_max,omitempty"`
+	FareMin  *wrapperspb.FloatValue `protobuf:"bytes,6,opt,name=fare_min,json=fareMin,proto3" json:"fare_min,omitempty"`
+	FareMax  *wrapperspb.FloatValue `protobuf:"bytes,7,opt,name=fare_max,json=fareMax,proto3" json:"fare_max,omitempty"`
+}
+
+func (x *PeopleFilter) Reset() {
+	*x = PeopleFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeopleFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeopleFilter) ProtoMessage() {}
+
+func (x *PeopleFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeopleFilter.ProtoReflect.Descriptor instead.
+func (*PeopleFilter) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PeopleFilter) GetSurvived() *wrapperspb.BoolValue {
+	if x != nil {
+		return x.Survived
+	}
+	return nil
+}
+
+func (x *PeopleFilter) GetPclass() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.Pclass
+	}
+	return nil
+}
+
+func (x *PeopleFilter) GetSex() string {
+	if x != nil {
+		return x.Sex
+	}
+	return ""
+}
+
+func (x *PeopleFilter) GetAgeMin() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.AgeMin
+	}
+	return nil
+}
+
+func (x *PeopleFilter) GetAgeMax() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.AgeMax
+	}
+	return nil
+}
+
+func (x *PeopleFilter) GetFareMin() *wrapperspb.FloatValue {
+	if x != nil {
+		return x.FareMin
+	}
+	return nil
+}
+
+func (x *PeopleFilter) GetFareMax() *wrapperspb.FloatValue {
+	if x != nil {
+		return x.FareMax
+	}
+	return nil
+}
+
+// The PostPeople request contains the passenger to create.
+type PostPeopleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	People *People `protobuf:"bytes,1,opt,name=people,proto3" json:"people,omitempty"`
+}
+
+func (x *PostPeopleRequest) Reset() {
+	*x = PostPeopleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PostPeopleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostPeopleRequest) ProtoMessage() {}
+
+func (x *PostPeopleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostPeopleRequest.ProtoReflect.Descriptor instead.
+func (*PostPeopleRequest) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PostPeopleRequest) GetPeople() *People {
+	if x != nil {
+		return x.People
+	}
+	return nil
+}
+
+// The PostPeople response contains the uuid of the created passenger.
+type PostPeopleReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+}
+
+func (x *PostPeopleReply) Reset() {
+	*x = PostPeopleReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PostPeopleReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostPeopleReply) ProtoMessage() {}
+
+func (x *PostPeopleReply) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostPeopleReply.ProtoReflect.Descriptor instead.
+func (*PostPeopleReply) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PostPeopleReply) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+// The GetPeopleByID request contains the uuid of the passenger.
+type GetPeopleByIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+}
+
+func (x *GetPeopleByIDRequest) Reset() {
+	*x = GetPeopleByIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPeopleByIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeopleByIDRequest) ProtoMessage() {}
+
+func (x *GetPeopleByIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeopleByIDRequest.ProtoReflect.Descriptor instead.
+func (*GetPeopleByIDRequest) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetPeopleByIDRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+// The GetPeopleByID response contains the passenger.
+type GetPeopleByIDReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	People *People `protobuf:"bytes,1,opt,name=people,proto3" json:"people,omitempty"`
+}
+
+func (x *GetPeopleByIDReply) Reset() {
+	*x = GetPeopleByIDReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPeopleByIDReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeopleByIDReply) ProtoMessage() {}
+
+func (x *GetPeopleByIDReply) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeopleByIDReply.ProtoReflect.Descriptor instead.
+func (*GetPeopleByIDReply) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPeopleByIDReply) GetPeople() *People {
+	if x != nil {
+		return x.People
+	}
+	return nil
+}
+
+// The PutPeople request contains the uuid and the passenger to store.
+type PutPeopleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid   string  `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	People *People `protobuf:"bytes,2,opt,name=people,proto3" json:"people,omitempty"`
+}
+
+func (x *PutPeopleRequest) Reset() {
+	*x = PutPeopleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutPeopleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutPeopleRequest) ProtoMessage() {}
+
+func (x *PutPeopleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutPeopleRequest.ProtoReflect.Descriptor instead.
+func (*PutPeopleRequest) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PutPeopleRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *PutPeopleRequest) GetPeople() *People {
+	if x != nil {
+		return x.People
+	}
+	return nil
+}
+
+// The PutPeople response is empty on success.
+type PutPeopleReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PutPeopleReply) Reset() {
+	*x = PutPeopleReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutPeopleReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutPeopleReply) ProtoMessage() {}
+
+func (x *PutPeopleReply) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutPeopleReply.ProtoReflect.Descriptor instead.
+func (*PutPeopleReply) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{7}
+}
+
+// The PatchPeople request contains the uuid and the fields to update.
+type PatchPeopleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid   string  `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	People *People `protobuf:"bytes,2,opt,name=people,proto3" json:"people,omitempty"`
+}
+
+func (x *PatchPeopleRequest) Reset() {
+	*x = PatchPeopleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PatchPeopleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchPeopleRequest) ProtoMessage() {}
+
+func (x *PatchPeopleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchPeopleRequest.ProtoReflect.Descriptor instead.
+func (*PatchPeopleRequest) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PatchPeopleRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *PatchPeopleRequest) GetPeople() *People {
+	if x != nil {
+		return x.People
+	}
+	return nil
+}
+
+// The PatchPeople response is empty on success.
+type PatchPeopleReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PatchPeopleReply) Reset() {
+	*x = PatchPeopleReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PatchPeopleReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchPeopleReply) ProtoMessage() {}
+
+func (x *PatchPeopleReply) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchPeopleReply.ProtoReflect.Descriptor instead.
+func (*PatchPeopleReply) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{9}
+}
+
+// The DeletePeople request contains the uuid of the passenger and, to only
+// delete that version of it, its version.
+type DeletePeopleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid    string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Version int32  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *DeletePeopleRequest) Reset() {
+	*x = DeletePeopleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeletePeopleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePeopleRequest) ProtoMessage() {}
+
+func (x *DeletePeopleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePeopleRequest.ProtoReflect.Descriptor instead.
+func (*DeletePeopleRequest) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeletePeopleRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *DeletePeopleRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// The DeletePeople response contains the uuid of the deleted passenger.
+type DeletePeopleReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+}
+
+func (x *DeletePeopleReply) Reset() {
+	*x = DeletePeopleReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeletePeopleReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePeopleReply) ProtoMessage() {}
+
+func (x *DeletePeopleReply) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePeopleReply.ProtoReflect.Descriptor instead.
+func (*DeletePeopleReply) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeletePeopleReply) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+// The GetPeople request describes the page to return. The offset and the
+// cursor are mutually exclusive, and sort is a comma separated list of
+// fields, each one optionally prefixed by '-' for descending order. The
+// deleted passengers are left out, unless include_deleted is set.
+type GetPeopleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Limit          int32         `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset         int32         `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Cursor         string        `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Filter         *PeopleFilter `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	Sort           string        `protobuf:"bytes,5,opt,name=sort,proto3" json:"sort,omitempty"`
+	IncludeDeleted bool          `protobuf:"varint,6,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+}
+
+func (x *GetPeopleRequest) Reset() {
+	*x = GetPeopleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPeopleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeopleRequest) ProtoMessage() {}
+
+func (x *GetPeopleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeopleRequest.ProtoReflect.Descriptor instead.
+func (*GetPeopleRequest) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetPeopleRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetPeopleRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GetPeopleRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetPeopleRequest) GetFilter() *PeopleFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *GetPeopleRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *GetPeopleRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+// The GetPeople response contains a page of passengers, the cursor of the
+// next page, if any, and the number of passengers matching the filter.
+type GetPeopleReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	People     []*People `protobuf:"bytes,1,rep,name=people,proto3" json:"people,omitempty"`
+	NextCursor string    `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	Total      int32     `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+func (x *GetPeopleReply) Reset() {
+	*x = GetPeopleReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPeopleReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeopleReply) ProtoMessage() {}
+
+func (x *GetPeopleReply) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeopleReply.ProtoReflect.Descriptor instead.
+func (*GetPeopleReply) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetPeopleReply) GetPeople() []*People {
+	if x != nil {
+		return x.People
+	}
+	return nil
+}
+
+func (x *GetPeopleReply) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *GetPeopleReply) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// The ListPeople request describes the passengers to stream, and their order.
+// The deleted passengers are left out, unless include_deleted is set.
+type ListPeopleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filter         *PeopleFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Sort           string        `protobuf:"bytes,2,opt,name=sort,proto3" json:"sort,omitempty"`
+	IncludeDeleted bool          `protobuf:"varint,3,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+}
+
+func (x *ListPeopleRequest) Reset() {
+	*x = ListPeopleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_titanic_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPeopleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPeopleRequest) ProtoMessage() {}
+
+func (x *ListPeopleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_titanic_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPeopleRequest.ProtoReflect.Descriptor instead.
+func (*ListPeopleRequest) Descriptor() ([]byte, []int) {
+	return file_titanic_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListPeopleRequest) GetFilter() *PeopleFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListPeopleRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListPeopleRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+var File_titanic_proto protoreflect.FileDescriptor
+
+var file_titanic_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x74, 0x69, 0x74, 0x61, 0x6e, 0x69, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x02, 0x70, 0x62, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x77, 0x72, 0x61, 0x70, 0x70, 0x65, 0x72, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xd3, 0x03, 0x0a, 0x06, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75,
+	0x69, 0x64, 0x12, 0x36, 0x0a, 0x08, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76, 0x65, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x52, 0x08, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x70, 0x63,
+	0x6c, 0x61, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74,
+	0x33, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x06, 0x70, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x73, 0x65, 0x78, 0x12, 0x2d, 0x0a, 0x03, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74, 0x33, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x03, 0x61, 0x67, 0x65, 0x12, 0x53, 0x0a, 0x17, 0x73, 0x69, 0x62, 0x6c, 0x69, 0x6e, 0x67, 0x73,
+	0x5f, 0x73, 0x70, 0x6f, 0x75, 0x73, 0x65, 0x73, 0x5f, 0x61, 0x62, 0x72, 0x6f, 0x61, 0x64, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74, 0x33, 0x32, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x52, 0x15, 0x73, 0x69, 0x62, 0x6c, 0x69, 0x6e, 0x67, 0x73, 0x53, 0x70, 0x6f, 0x75,
+	0x73, 0x65, 0x73, 0x41, 0x62, 0x72, 0x6f, 0x61, 0x64, 0x12, 0x53, 0x0a, 0x17, 0x70, 0x61, 0x72,
+	0x65, 0x6e, 0x74, 0x73, 0x5f, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x5f, 0x61, 0x62,
+	0x6f, 0x61, 0x72, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74,
+	0x33, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x15, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x73,
+	0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x41, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x12, 0x2f,
+	0x0a, 0x04, 0x66, 0x61, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46,
+	0x6c, 0x6f, 0x61, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x66, 0x61, 0x72, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xe9, 0x02, 0x0a, 0x0c, 0x50, 0x65,
+	0x6f, 0x70, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x36, 0x0a, 0x08, 0x73, 0x75,
+	0x72, 0x76, 0x69, 0x76, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x42,
+	0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x08, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76,
+	0x65, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x70, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74, 0x33, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x06, 0x70, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x78, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x65, 0x78, 0x12, 0x34, 0x0a, 0x07, 0x61, 0x67, 0x65,
+	0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74,
+	0x33, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x06, 0x61, 0x67, 0x65, 0x4d, 0x69, 0x6e, 0x12,
+	0x34, 0x0a, 0x07, 0x61, 0x67, 0x65, 0x5f, 0x6d, 0x61, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74, 0x33, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x06, 0x61,
+	0x67, 0x65, 0x4d, 0x61, 0x78, 0x12, 0x36, 0x0a, 0x08, 0x66, 0x61, 0x72, 0x65, 0x5f, 0x6d, 0x69,
+	0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x6c, 0x6f, 0x61, 0x74, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x52, 0x07, 0x66, 0x61, 0x72, 0x65, 0x4d, 0x69, 0x6e, 0x12, 0x36, 0x0a,
+	0x08, 0x66, 0x61, 0x72, 0x65, 0x5f, 0x6d, 0x61, 0x78, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x46, 0x6c, 0x6f, 0x61, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x07, 0x66, 0x61,
+	0x72, 0x65, 0x4d, 0x61, 0x78, 0x22, 0x37, 0x0a, 0x11, 0x50, 0x6f, 0x73, 0x74, 0x50, 0x65, 0x6f,
+	0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x06, 0x70, 0x65,
+	0x6f, 0x70, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e,
+	0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x22, 0x25,
+	0x0a, 0x0f, 0x50, 0x6f, 0x73, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x75, 0x75, 0x69, 0x64, 0x22, 0x2a, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6f, 0x70,
+	0x6c, 0x65, 0x42, 0x79, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75, 0x69,
+	0x64, 0x22, 0x38, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x42, 0x79,
+	0x49, 0x44, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x22, 0x0a, 0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x65, 0x6f,
+	0x70, 0x6c, 0x65, 0x52, 0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x22, 0x4a, 0x0a, 0x10, 0x50,
+	0x75, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75,
+	0x75, 0x69, 0x64, 0x12, 0x22, 0x0a, 0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52,
+	0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x22, 0x10, 0x0a, 0x0e, 0x50, 0x75, 0x74, 0x50, 0x65,
+	0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x4c, 0x0a, 0x12, 0x50, 0x61, 0x74,
+	0x63, 0x68, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75,
+	0x75, 0x69, 0x64, 0x12, 0x22, 0x0a, 0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52,
+	0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x22, 0x12, 0x0a, 0x10, 0x50, 0x61, 0x74, 0x63, 0x68,
+	0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x43, 0x0a, 0x13, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x22, 0x27, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x22, 0xbf, 0x01, 0x0a, 0x10, 0x47, 0x65,
+	0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x75,
+	0x72, 0x73, 0x6f, 0x72, 0x12, 0x28, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6f,
+	0x72, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x64, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0x6b, 0x0a, 0x0e, 0x47,
+	0x65, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x22, 0x0a,
+	0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e,
+	0x70, 0x62, 0x2e, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x06, 0x70, 0x65, 0x6f, 0x70, 0x6c,
+	0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73,
+	0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0x7a, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74,
+	0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a,
+	0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x70, 0x62, 0x2e, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52,
+	0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x69,
+	0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x64, 0x32, 0xb2, 0x03, 0x0a, 0x07, 0x54, 0x69, 0x74, 0x61, 0x6e, 0x69, 0x63,
+	0x12, 0x3a, 0x0a, 0x0a, 0x50, 0x6f, 0x73, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x12, 0x15,
+	0x2e, 0x70, 0x62, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x50,
+	0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x0d,
+	0x47, 0x65, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x42, 0x79, 0x49, 0x44, 0x12, 0x18, 0x2e,
+	0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x42, 0x79, 0x49, 0x44,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74,
+	0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x42, 0x79, 0x49, 0x44, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x37, 0x0a, 0x09, 0x50, 0x75, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x12, 0x14,
+	0x2e, 0x70, 0x62, 0x2e, 0x50, 0x75, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x75, 0x74, 0x50, 0x65, 0x6f,
+	0x70, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x0b, 0x50, 0x61,
+	0x74, 0x63, 0x68, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x12, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x50,
+	0x61, 0x74, 0x63, 0x68, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x74, 0x63, 0x68, 0x50, 0x65, 0x6f, 0x70,
+	0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x0c, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x12, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x65,
+	0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x09, 0x47,
+	0x65, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65,
+	0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12,
+	0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6f, 0x70, 0x6c, 0x65, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x33, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x6f, 0x70,
+	0x6c, 0x65, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x6f, 0x70,
+	0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x50,
+	0x65, 0x6f, 0x70, 0x6c, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74,
+	0x6c, 0x61, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x79, 0x70, 0x65, 0x72, 0x64, 0x2f, 0x74,
+	0x69, 0x74, 0x61, 0x6e, 0x69, 0x63, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74,
+	0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_titanic_proto_rawDescOnce sync.Once
+	file_titanic_proto_rawDescData = file_titanic_proto_rawDesc
+)
+
+func file_titanic_proto_rawDescGZIP() []byte {
+	file_titanic_proto_rawDescOnce.Do(func() {
+		file_titanic_proto_rawDescData = protoimpl.X.CompressGZIP(file_titanic_proto_rawDescData)
+	})
+	return file_titanic_proto_rawDescData
+}
+
+var file_titanic_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_titanic_proto_goTypes = []interface{}{
+	(*People)(nil),                // 0: pb.People
+	(*PeopleFilter)(nil),          // 1: pb.PeopleFilter
+	(*PostPeopleRequest)(nil),     // 2: pb.PostPeopleRequest
+	(*PostPeopleReply)(nil),       // 3: pb.PostPeopleReply
+	(*GetPeopleByIDRequest)(nil),  // 4: pb.GetPeopleByIDRequest
+	(*GetPeopleByIDReply)(nil),    // 5: pb.GetPeopleByIDReply
+	(*PutPeopleRequest)(nil),      // 6: pb.PutPeopleRequest
+	(*PutPeopleReply)(nil),        // 7: pb.PutPeopleReply
+	(*PatchPeopleRequest)(nil),    // 8: pb.PatchPeopleRequest
+	(*PatchPeopleReply)(nil),      // 9: pb.PatchPeopleReply
+	(*DeletePeopleRequest)(nil),   // 10: pb.DeletePeopleRequest
+	(*DeletePeopleReply)(nil),     // 11: pb.DeletePeopleReply
+	(*GetPeopleRequest)(nil),      // 12: pb.GetPeopleRequest
+	(*GetPeopleReply)(nil),        // 13: pb.GetPeopleReply
+	(*ListPeopleRequest)(nil),     // 14: pb.ListPeopleRequest
+	(*wrapperspb.BoolValue)(nil),  // 15: google.protobuf.BoolValue
+	(*wrapperspb.Int32Value)(nil), // 16: google.protobuf.Int32Value
+	(*wrapperspb.FloatValue)(nil), // 17: google.protobuf.FloatValue
+}
+var file_titanic_proto_depIdxs = []int32{
+	15, // 0: pb.People.survived:type_name -> google.protobuf.BoolValue
+	16, // 1: pb.People.pclass:type_name -> google.protobuf.Int32Value
+	16, // 2: pb.People.age:type_name -> google.protobuf.Int32Value
+	16, // 3: pb.People.siblings_spouses_abroad:type_name -> google.protobuf.Int32Value
+	16, // 4: pb.People.parents_children_aboard:type_name -> google.protobuf.Int32Value
+	17, // 5: pb.People.fare:type_name -> google.protobuf.FloatValue
+	15, // 6: pb.PeopleFilter.survived:type_name -> google.protobuf.BoolValue
+	16, // 7: pb.PeopleFilter.pclass:type_name -> google.protobuf.Int32Value
+	16, // 8: pb.PeopleFilter.age_min:type_name -> google.protobuf.Int32Value
+	16, // 9: pb.PeopleFilter.age_max:type_name -> google.protobuf.Int32Value
+	17, // 10: pb.PeopleFilter.fare_min:type_name -> google.protobuf.FloatValue
+	17, // 11: pb.PeopleFilter.fare_max:type_name -> google.protobuf.FloatValue
+	0,  // 12: pb.PostPeopleRequest.people:type_name -> pb.People
+	0,  // 13: pb.GetPeopleByIDReply.people:type_name -> pb.People
+	0,  // 14: pb.PutPeopleRequest.people:type_name -> pb.People
+	0,  // 15: pb.PatchPeopleRequest.people:type_name -> pb.People
+	1,  // 16: pb.GetPeopleRequest.filter:type_name -> pb.PeopleFilter
+	0,  // 17: pb.GetPeopleReply.people:type_name -> pb.People
+	1,  // 18: pb.ListPeopleRequest.filter:type_name -> pb.PeopleFilter
+	2,  // 19: pb.Titanic.PostPeople:input_type -> pb.PostPeopleRequest
+	4,  // 20: pb.Titanic.GetPeopleByID:input_type -> pb.GetPeopleByIDRequest
+	6,  // 21: pb.Titanic.PutPeople:input_type -> pb.PutPeopleRequest
+	8,  // 22: pb.Titanic.PatchPeople:input_type -> pb.PatchPeopleRequest
+	10, // 23: pb.Titanic.DeletePeople:input_type -> pb.DeletePeopleRequest
+	12, // 24: pb.Titanic.GetPeople:input_type -> pb.GetPeopleRequest
+	14, // 25: pb.Titanic.ListPeople:input_type -> pb.ListPeopleRequest
+	3,  // 26: pb.Titanic.PostPeople:output_type -> pb.PostPeopleReply
+	5,  // 27: pb.Titanic.GetPeopleByID:output_type -> pb.GetPeopleByIDReply
+	7,  // 28: pb.Titanic.PutPeople:output_type -> pb.PutPeopleReply
+	9,  // 29: pb.Titanic.PatchPeople:output_type -> pb.PatchPeopleReply
+	11, // 30: pb.Titanic.DeletePeople:output_type -> pb.DeletePeopleReply
+	13, // 31: pb.Titanic.GetPeople:output_type -> pb.GetPeopleReply
+	0,  // 32: pb.Titanic.ListPeople:output_type -> pb.People
+	26, // [26:33] is the sub-list for method output_type
+	19, // [19:26] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_titanic_proto_init() }
+func file_titanic_proto_init() {
+	if File_titanic_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_titanic_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*People); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeopleFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PostPeopleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PostPeopleReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPeopleByIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPeopleByIDReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutPeopleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutPeopleReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PatchPeopleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PatchPeopleReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeletePeopleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeletePeopleReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPeopleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPeopleReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_titanic_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPeopleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_titanic_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_titanic_proto_goTypes,
+		DependencyIndexes: file_titanic_proto_depIdxs,
+		MessageInfos:      file_titanic_proto_msgTypes,
+	}.Build()
+	File_titanic_proto = out.File
+	file_titanic_proto_rawDesc = nil
+	file_titanic_proto_goTypes = nil
+	file_titanic_proto_depIdxs = nil
+}
@@ -0,0 +1,373 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: titanic.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Titanic_PostPeople_FullMethodName    = "/pb.Titanic/PostPeople"
+	Titanic_GetPeopleByID_FullMethodName = "/pb.Titanic/GetPeopleByID"
+	Titanic_PutPeople_FullMethodName     = "/pb.Titanic/PutPeople"
+	Titanic_PatchPeople_FullMethodName   = "/pb.Titanic/PatchPeople"
+	Titanic_DeletePeople_FullMethodName  = "/pb.Titanic/DeletePeople"
+	Titanic_GetPeople_FullMethodName     = "/pb.Titanic/GetPeople"
+	Titanic_ListPeople_FullMethodName    = "/pb.Titanic/ListPeople"
+)
+
+// TitanicClient is the client API for Titanic service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TitanicClient interface {
+	// Creates a passenger, and returns its uuid.
+	PostPeople(ctx context.Context, in *PostPeopleRequest, opts ...grpc.CallOption) (*PostPeopleReply, error)
+	// Returns a single passenger.
+	GetPeopleByID(ctx context.Context, in *GetPeopleByIDRequest, opts ...grpc.CallOption) (*GetPeopleByIDReply, error)
+	// Creates or replaces a passenger.
+	PutPeople(ctx context.Context, in *PutPeopleRequest, opts ...grpc.CallOption) (*PutPeopleReply, error)
+	// Updates the fields of a passenger that are set in the request.
+	PatchPeople(ctx context.Context, in *PatchPeopleRequest, opts ...grpc.CallOption) (*PatchPeopleReply, error)
+	// Deletes a passenger, and returns its uuid.
+	DeletePeople(ctx context.Context, in *DeletePeopleRequest, opts ...grpc.CallOption) (*DeletePeopleReply, error)
+	// Returns a page of the passengers.
+	GetPeople(ctx context.Context, in *GetPeopleRequest, opts ...grpc.CallOption) (*GetPeopleReply, error)
+	// Streams every passenger, ignoring the pagination.
+	ListPeople(ctx context.Context, in *ListPeopleRequest, opts ...grpc.CallOption) (Titanic_ListPeopleClient, error)
+}
+
+type titanicClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTitanicClient(cc grpc.ClientConnInterface) TitanicClient {
+	return &titanicClient{cc}
+}
+
+func (c *titanicClient) PostPeople(ctx context.Context, in *PostPeopleRequest, opts ...grpc.CallOption) (*PostPeopleReply, error) {
+	out := new(PostPeopleReply)
+	err := c.cc.Invoke(ctx, Titanic_PostPeople_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *titanicClient) GetPeopleByID(ctx context.Context, in *GetPeopleByIDRequest, opts ...grpc.CallOption) (*GetPeopleByIDReply, error) {
+	out := new(GetPeopleByIDReply)
+	err := c.cc.Invoke(ctx, Titanic_GetPeopleByID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *titanicClient) PutPeople(ctx context.Context, in *PutPeopleRequest, opts ...grpc.CallOption) (*PutPeopleReply, error) {
+	out := new(PutPeopleReply)
+	err := c.cc.Invoke(ctx, Titanic_PutPeople_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *titanicClient) PatchPeople(ctx context.Context, in *PatchPeopleRequest, opts ...grpc.CallOption) (*PatchPeopleReply, error) {
+	out := new(PatchPeopleReply)
+	err := c.cc.Invoke(ctx, Titanic_PatchPeople_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *titanicClient) DeletePeople(ctx context.Context, in *DeletePeopleRequest, opts ...grpc.CallOption) (*DeletePeopleReply, error) {
+	out := new(DeletePeopleReply)
+	err := c.cc.Invoke(ctx, Titanic_DeletePeople_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *titanicClient) GetPeople(ctx context.Context, in *GetPeopleRequest, opts ...grpc.CallOption) (*GetPeopleReply, error) {
+	out := new(GetPeopleReply)
+	err := c.cc.Invoke(ctx, Titanic_GetPeople_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *titanicClient) ListPeople(ctx context.Context, in *ListPeopleRequest, opts ...grpc.CallOption) (Titanic_ListPeopleClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Titanic_ServiceDesc.Streams[0], Titanic_ListPeople_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &titanicListPeopleClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Titanic_ListPeopleClient interface {
+	Recv() (*People, error)
+	grpc.ClientStream
+}
+
+type titanicListPeopleClient struct {
+	grpc.ClientStream
+}
+
+func (x *titanicListPeopleClient) Recv() (*People, error) {
+	m := new(People)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TitanicServer is the server API for Titanic service.
+// All implementations must embed UnimplementedTitanicServer
+// for forward compatibility
+type TitanicServer interface {
+	// Creates a passenger, and returns its uuid.
+	PostPeople(context.Context, *PostPeopleRequest) (*PostPeopleReply, error)
+	// Returns a single passenger.
+	GetPeopleByID(context.Context, *GetPeopleByIDRequest) (*GetPeopleByIDReply, error)
+	// Creates or replaces a passenger.
+	PutPeople(context.Context, *PutPeopleRequest) (*PutPeopleReply, error)
+	// Updates the fields of a passenger that are set in the request.
+	PatchPeople(context.Context, *PatchPeopleRequest) (*PatchPeopleReply, error)
+	// Deletes a passenger, and returns its uuid.
+	DeletePeople(context.Context, *DeletePeopleRequest) (*DeletePeopleReply, error)
+	// Returns a page of the passengers.
+	GetPeople(context.Context, *GetPeopleRequest) (*GetPeopleReply, error)
+	// Streams every passenger, ignoring the pagination.
+	ListPeople(*ListPeopleRequest, Titanic_ListPeopleServer) error
+	mustEmbedUnimplementedTitanicServer()
+}
+
+// UnimplementedTitanicServer must be embedded to have forward compatible implementations.
+type UnimplementedTitanicServer struct {
+}
+
+func (UnimplementedTitanicServer) PostPeople(context.Context, *PostPeopleRequest) (*PostPeopleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostPeople not implemented")
+}
+func (UnimplementedTitanicServer) GetPeopleByID(context.Context, *GetPeopleByIDRequest) (*GetPeopleByIDReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeopleByID not implemented")
+}
+func (UnimplementedTitanicServer) PutPeople(context.Context, *PutPeopleRequest) (*PutPeopleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutPeople not implemented")
+}
+func (UnimplementedTitanicServer) PatchPeople(context.Context, *PatchPeopleRequest) (*PatchPeopleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchPeople not implemented")
+}
+func (UnimplementedTitanicServer) DeletePeople(context.Context, *DeletePeopleRequest) (*DeletePeopleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePeople not implemented")
+}
+func (UnimplementedTitanicServer) GetPeople(context.Context, *GetPeopleRequest) (*GetPeopleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeople not implemented")
+}
+func (UnimplementedTitanicServer) ListPeople(*ListPeopleRequest, Titanic_ListPeopleServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListPeople not implemented")
+}
+func (UnimplementedTitanicServer) mustEmbedUnimplementedTitanicServer() {}
+
+// UnsafeTitanicServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TitanicServer will
+// result in compilation errors.
+type UnsafeTitanicServer interface {
+	mustEmbedUnimplementedTitanicServer()
+}
+
+func RegisterTitanicServer(s grpc.ServiceRegistrar, srv TitanicServer) {
+	s.RegisterService(&Titanic_ServiceDesc, srv)
+}
+
+func _Titanic_PostPeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostPeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TitanicServer).PostPeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Titanic_PostPeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TitanicServer).PostPeople(ctx, req.(*PostPeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Titanic_GetPeopleByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPeopleByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TitanicServer).GetPeopleByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Titanic_GetPeopleByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TitanicServer).GetPeopleByID(ctx, req.(*GetPeopleByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Titanic_PutPeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutPeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TitanicServer).PutPeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Titanic_PutPeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TitanicServer).PutPeople(ctx, req.(*PutPeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Titanic_PatchPeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchPeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TitanicServer).PatchPeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Titanic_PatchPeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TitanicServer).PatchPeople(ctx, req.(*PatchPeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Titanic_DeletePeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TitanicServer).DeletePeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Titanic_DeletePeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TitanicServer).DeletePeople(ctx, req.(*DeletePeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Titanic_GetPeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TitanicServer).GetPeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Titanic_GetPeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TitanicServer).GetPeople(ctx, req.(*GetPeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Titanic_ListPeople_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPeopleRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TitanicServer).ListPeople(m, &titanicListPeopleServer{stream})
+}
+
+type Titanic_ListPeopleServer interface {
+	Send(*People) error
+	grpc.ServerStream
+}
+
+type titanicListPeopleServer struct {
+	grpc.ServerStream
+}
+
+func (x *titanicListPeopleServer) Send(m *People) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Titanic_ServiceDesc is the grpc.ServiceDesc for Titanic service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Titanic_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.Titanic",
+	HandlerType: (*TitanicServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PostPeople",
+			Handler:    _Titanic_PostPeople_Handler,
+		},
+		{
+			MethodName: "GetPeopleByID",
+			Handler:    _Titanic_GetPeopleByID_Handler,
+		},
+		{
+			MethodName: "PutPeople",
+			Handler:    _Titanic_PutPeople_Handler,
+		},
+		{
+			MethodName: "PatchPeople",
+			Handler:    _Titanic_PatchPeople_Handler,
+		},
+		{
+			MethodName: "DeletePeople",
+			Handler:    _Titanic_DeletePeople_Handler,
+		},
+		{
+			MethodName: "GetPeople",
+			Handler:    _Titanic_GetPeople_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListPeople",
+			Handler:       _Titanic_ListPeople_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "titanic.proto",
+}
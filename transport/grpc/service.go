@@ -0,0 +1,535 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/audit"
+	"gitlab.com/hyperd/titanic/auth"
+	"gitlab.com/hyperd/titanic/ratelimit"
+	"gitlab.com/hyperd/titanic/transport"
+	"gitlab.com/hyperd/titanic/transport/grpc/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ErrBadUUID is returned when a request carries a malformed uuid.
+var ErrBadUUID = titanic.ErrMalformedRequest.WithDetail("malformed uuid")
+
+type grpcServer struct {
+	pb.UnimplementedTitanicServer
+
+	postPeople    kitgrpc.Handler
+	getPeopleByID kitgrpc.Handler
+	putPeople     kitgrpc.Handler
+	patchPeople   kitgrpc.Handler
+	deletePeople  kitgrpc.Handler
+	getPeople     kitgrpc.Handler
+	listPeople    endpoint.Endpoint
+	before        []kitgrpc.ServerRequestFunc
+	logger        log.Logger
+}
+
+// MakeGRPCServer makes all of the service endpoints available as a
+// pb.TitanicServer. The changes made by each call are attributed to its
+// x-actor and x-request-id metadata. The bearer tokens of the authorization
+// metadata, and the API keys of the x-api-key one, are verified by the given
+// authenticator, unless nil. The calls of each client are rate limited by
+// the given limiter, unless nil.
+func MakeGRPCServer(s titanic.Service, logger log.Logger, authenticator *auth.Authenticator, limiter *ratelimit.Limiter) pb.TitanicServer {
+	e := transport.MakeServerEndpoints(s)
+	before := []kitgrpc.ServerRequestFunc{recordOrigin}
+	if authenticator != nil {
+		before = append(before, authenticate(authenticator))
+	}
+	if limiter != nil {
+		e = transport.RateLimit(e, limiter)
+		before = append(before, limitClient)
+	}
+	options := []kitgrpc.ServerOption{
+		kitgrpc.ServerErrorLogger(logger),
+		kitgrpc.ServerBefore(before...),
+	}
+
+	return &grpcServer{
+		postPeople: kitgrpc.NewServer(
+			e.PostPeopleEndpoint,
+			decodePostPeopleRequest,
+			encodePostPeopleResponse,
+			options...,
+		),
+		getPeopleByID: kitgrpc.NewServer(
+			e.GetPeopleByIDEndpoint,
+			decodeGetPeopleByIDRequest,
+			encodeGetPeopleByIDResponse,
+			options...,
+		),
+		putPeople: kitgrpc.NewServer(
+			e.PutPeopleEndpoint,
+			decodePutPeopleRequest,
+			encodePutPeopleResponse,
+			options...,
+		),
+		patchPeople: kitgrpc.NewServer(
+			e.PatchPeopleEndpoint,
+			decodePatchPeopleRequest,
+			encodePatchPeopleResponse,
+			options...,
+		),
+		deletePeople: kitgrpc.NewServer(
+			e.DeletePeopleEndpoint,
+			decodeDeletePeopleRequest,
+			encodeDeletePeopleResponse,
+			options...,
+		),
+		getPeople: kitgrpc.NewServer(
+			e.GetPeopleEndpoint,
+			decodeGetPeopleRequest,
+			encodeGetPeopleResponse,
+			options...,
+		),
+		// go-kit doesn't handle streams: the export endpoint is called
+		// directly, and its stream forwarded to the client.
+		listPeople: e.ExportPeopleEndpoint,
+		before:     before,
+		logger:     logger,
+	}
+}
+
+// limitClient attaches the address of the client to the context, so that
+// the anonymous calls are limited by address.
+func limitClient(ctx context.Context, _ metadata.MD) context.Context {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+	}
+	return ratelimit.NewContext(ctx, addr)
+}
+
+// recordOrigin attaches the audit.Origin of the call, read from its
+// metadata, to its context.
+func recordOrigin(ctx context.Context, md metadata.MD) context.Context {
+	o := audit.Origin{}
+	if v := md.Get("x-actor"); len(v) > 0 {
+		o.Actor = v[0]
+	}
+	if v := md.Get("x-request-id"); len(v) > 0 {
+		o.RequestID = v[0]
+	} else {
+		o.RequestID = uuid.New().String()
+	}
+	return audit.NewContext(ctx, o)
+}
+
+// authenticate verifies the bearer token, or else the API key, of the call,
+// if any, attributing the changes it makes to the subject of the
+// credentials, in place of its x-actor metadata. Calls with invalid
+// credentials are rejected by the authorization middleware.
+func authenticate(a *auth.Authenticator) kitgrpc.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		var token, key string
+		if v := md.Get("authorization"); len(v) > 0 {
+			token = auth.BearerToken(v[0])
+		}
+		if v := md.Get("x-api-key"); len(v) > 0 {
+			key = v[0]
+		}
+		ctx = a.Authenticate(ctx, token, key)
+		if claims, err := auth.FromContext(ctx); err == nil {
+			o := audit.FromContext(ctx)
+			o.Actor = claims.Actor()
+			ctx = audit.NewContext(ctx, o)
+		}
+		return ctx
+	}
+}
+
+func (s *grpcServer) PostPeople(ctx context.Context, req *pb.PostPeopleRequest) (*pb.PostPeopleReply, error) {
+	_, rep, err := s.postPeople.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.PostPeopleReply), nil
+}
+
+func (s *grpcServer) GetPeopleByID(ctx context.Context, req *pb.GetPeopleByIDRequest) (*pb.GetPeopleByIDReply, error) {
+	_, rep, err := s.getPeopleByID.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.GetPeopleByIDReply), nil
+}
+
+func (s *grpcServer) PutPeople(ctx context.Context, req *pb.PutPeopleRequest) (*pb.PutPeopleReply, error) {
+	_, rep, err := s.putPeople.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.PutPeopleReply), nil
+}
+
+func (s *grpcServer) PatchPeople(ctx context.Context, req *pb.PatchPeopleRequest) (*pb.PatchPeopleReply, error) {
+	_, rep, err := s.patchPeople.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.PatchPeopleReply), nil
+}
+
+func (s *grpcServer) DeletePeople(ctx context.Context, req *pb.DeletePeopleRequest) (*pb.DeletePeopleReply, error) {
+	_, rep, err := s.deletePeople.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.DeletePeopleReply), nil
+}
+
+func (s *grpcServer) GetPeople(ctx context.Context, req *pb.GetPeopleRequest) (*pb.GetPeopleReply, error) {
+	_, rep, err := s.getPeople.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.GetPeopleReply), nil
+}
+
+func (s *grpcServer) ListPeople(req *pb.ListPeopleRequest, stream pb.Titanic_ListPeopleServer) error {
+	ctx := stream.Context()
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, f := range s.before {
+		ctx = f(ctx, md)
+	}
+
+	request, err := decodeListPeopleRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	response, err := s.listPeople(ctx, request)
+	if err != nil {
+		level.Error(s.logger).Log("method", "ListPeople", "err", err)
+		return encodeError(err)
+	}
+
+	resp := response.(transport.ExportPeopleResponse)
+	if resp.Err != nil {
+		return encodeError(resp.Err)
+	}
+	err = resp.Stream(func(p titanic.People) error {
+		return stream.Send(encodePeople(p))
+	})
+	if err != nil {
+		level.Error(s.logger).Log("method", "ListPeople", "err", err)
+		return encodeError(err)
+	}
+	return nil
+}
+
+func decodePostPeopleRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.PostPeopleRequest)
+	people, err := decodePeople(req.People)
+	if err != nil {
+		return nil, err
+	}
+	return transport.PostPeopleRequest{People: people}, nil
+}
+
+func decodeGetPeopleByIDRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.GetPeopleByIDRequest)
+	id, err := decodeUUID(req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+	return transport.GetPeopleByIDRequest{ID: id}, nil
+}
+
+func decodePutPeopleRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.PutPeopleRequest)
+	id, err := decodeUUID(req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+	people, err := decodePeople(req.People)
+	if err != nil {
+		return nil, err
+	}
+	return transport.PutPeopleRequest{ID: id, People: people}, nil
+}
+
+func decodePatchPeopleRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.PatchPeopleRequest)
+	id, err := decodeUUID(req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+	people, err := decodePeople(req.People)
+	if err != nil {
+		return nil, err
+	}
+	return transport.PatchPeopleRequest{ID: id, People: people}, nil
+}
+
+func decodeDeletePeopleRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.DeletePeopleRequest)
+	id, err := decodeUUID(req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+	return transport.DeletePeopleRequest{ID: id, Version: int(req.Version)}, nil
+}
+
+func decodeGetPeopleRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.GetPeopleRequest)
+	sort, err := titanic.ParseSort(req.Sort)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return transport.GetPeopleRequest{
+		Limit:          int(req.Limit),
+		Offset:         int(req.Offset),
+		Cursor:         req.Cursor,
+		Filter:         decodePeopleFilter(req.Filter),
+		Sort:           sort,
+		IncludeDeleted: req.IncludeDeleted,
+	}, nil
+}
+
+func decodeListPeopleRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ListPeopleRequest)
+	sort, err := titanic.ParseSort(req.Sort)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return transport.ExportPeopleRequest{
+		Filter:         decodePeopleFilter(req.Filter),
+		Sort:           sort,
+		IncludeDeleted: req.IncludeDeleted,
+	}, nil
+}
+
+func encodePostPeopleResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(transport.PostPeopleResponse)
+	if resp.Err != nil {
+		return nil, encodeError(resp.Err)
+	}
+	return &pb.PostPeopleReply{Uuid: resp.ID}, nil
+}
+
+func encodeGetPeopleByIDResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(transport.GetPeopleByIDResponse)
+	if resp.Err != nil {
+		return nil, encodeError(resp.Err)
+	}
+	return &pb.GetPeopleByIDReply{People: encodePeople(resp.People)}, nil
+}
+
+func encodePutPeopleResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(transport.PutPeopleResponse)
+	if resp.Err != nil {
+		return nil, encodeError(resp.Err)
+	}
+	return &pb.PutPeopleReply{}, nil
+}
+
+func encodePatchPeopleResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(transport.PatchPeopleResponse)
+	if resp.Err != nil {
+		return nil, encodeError(resp.Err)
+	}
+	return &pb.PatchPeopleReply{}, nil
+}
+
+func encodeDeletePeopleResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(transport.DeletePeopleResponse)
+	if resp.Err != nil {
+		return nil, encodeError(resp.Err)
+	}
+	return &pb.DeletePeopleReply{Uuid: resp.ID}, nil
+}
+
+func encodeGetPeopleResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(transport.GetPeopleResponse)
+	if resp.Err != nil {
+		return nil, encodeError(resp.Err)
+	}
+	reply := &pb.GetPeopleReply{
+		People:     make([]*pb.People, len(resp.People)),
+		NextCursor: resp.NextCursor,
+		Total:      int32(resp.Total),
+	}
+	for i, p := range resp.People {
+		reply.People[i] = encodePeople(p)
+	}
+	return reply, nil
+}
+
+// errorDomain qualifies the codes of the errors carried by the statuses.
+const errorDomain = "titanic"
+
+// grpcCodes are the gRPC codes of the error codes, mapped the way the HTTP
+// transport maps them to status codes; any other code is answered with
+// codes.Internal.
+var grpcCodes = map[titanic.Code]codes.Code{
+	titanic.CodeNotFound:         codes.NotFound,
+	titanic.CodeAlreadyExists:    codes.AlreadyExists,
+	titanic.CodeInconsistentIDs:  codes.InvalidArgument,
+	titanic.CodeInvalidQuery:     codes.InvalidArgument,
+	titanic.CodeMalformedRequest: codes.InvalidArgument,
+	titanic.CodeValidationFailed: codes.InvalidArgument,
+	titanic.CodeVersionConflict:  codes.FailedPrecondition,
+	titanic.CodeUnauthenticated:  codes.Unauthenticated,
+	titanic.CodeForbidden:        codes.PermissionDenied,
+	titanic.CodeRepositoryWrite:  codes.Unavailable,
+	titanic.CodeRepositoryRead:   codes.Unavailable,
+	titanic.CodeCanceled:         codes.Canceled,
+	titanic.CodeTimeout:          codes.DeadlineExceeded,
+	ratelimit.CodeRateLimited:    codes.ResourceExhausted,
+}
+
+// encodeError turns the service errors into gRPC status errors, detailed
+// by the code of the error and, for the validation errors, the problems of
+// each field. The errors without a code are answered as internal errors,
+// without disclosing their message.
+func encodeError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	c := titanic.ErrorCode(err)
+	code, ok := grpcCodes[c]
+	msg := err.Error()
+	if !ok {
+		code, msg = codes.Internal, "internal error"
+	}
+
+	st := status.New(code, msg)
+	details := []proto.Message{&errdetails.ErrorInfo{Reason: string(c), Domain: errorDomain}}
+	var verr *titanic.ValidationError
+	if errors.As(err, &verr) {
+		br := &errdetails.BadRequest{}
+		for _, f := range verr.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+			})
+		}
+		details = append(details, br)
+	}
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func decodeUUID(s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return id, encodeError(ErrBadUUID)
+	}
+	return id, nil
+}
+
+func decodePeople(p *pb.People) (titanic.People, error) {
+	var people titanic.People
+	if p == nil {
+		return people, nil
+	}
+	if p.Uuid != "" {
+		id, err := decodeUUID(p.Uuid)
+		if err != nil {
+			return people, err
+		}
+		people.ID = id
+	}
+	if p.Survived != nil {
+		survived := p.Survived.Value
+		people.Survived = &survived
+	}
+	people.Pclass = decodeInt(p.Pclass)
+	people.Name = p.Name
+	people.Sex = p.Sex
+	people.Age = decodeInt(p.Age)
+	people.SiblingsSpousesAbroad = decodeInt(p.SiblingsSpousesAbroad)
+	people.ParentsChildrenAboard = decodeInt(p.ParentsChildrenAboard)
+	people.Fare = decodeFloat(p.Fare)
+	people.Version = int(p.Version)
+	return people, nil
+}
+
+func encodePeople(p titanic.People) *pb.People {
+	people := &pb.People{
+		Uuid:                  p.ID.String(),
+		Pclass:                encodeInt(p.Pclass),
+		Name:                  p.Name,
+		Sex:                   p.Sex,
+		Age:                   encodeInt(p.Age),
+		SiblingsSpousesAbroad: encodeInt(p.SiblingsSpousesAbroad),
+		ParentsChildrenAboard: encodeInt(p.ParentsChildrenAboard),
+		Fare:                  encodeFloat(p.Fare),
+		Version:               int32(p.Version),
+	}
+	if p.Survived != nil {
+		people.Survived = wrapperspb.Bool(*p.Survived)
+	}
+	return people
+}
+
+func decodePeopleFilter(f *pb.PeopleFilter) titanic.PeopleFilter {
+	var filter titanic.PeopleFilter
+	if f == nil {
+		return filter
+	}
+	if f.Survived != nil {
+		survived := f.Survived.Value
+		filter.Survived = &survived
+	}
+	filter.Pclass = decodeInt(f.Pclass)
+	filter.Sex = f.Sex
+	filter.AgeMin = decodeInt(f.AgeMin)
+	filter.AgeMax = decodeInt(f.AgeMax)
+	filter.FareMin = decodeFloat(f.FareMin)
+	filter.FareMax = decodeFloat(f.FareMax)
+	return filter
+}
+
+func decodeInt(v *wrapperspb.Int32Value) *int {
+	if v == nil {
+		return nil
+	}
+	i := int(v.Value)
+	return &i
+}
+
+func encodeInt(i *int) *wrapperspb.Int32Value {
+	if i == nil {
+		return nil
+	}
+	return wrapperspb.Int32(int32(*i))
+}
+
+func decodeFloat(v *wrapperspb.FloatValue) *float32 {
+	if v == nil {
+		return nil
+	}
+	f := v.Value
+	return &f
+}
+
+func encodeFloat(f *float32) *wrapperspb.FloatValue {
+	if f == nil {
+		return nil
+	}
+	return wrapperspb.Float(*f)
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"gitlab.com/hyperd/titanic/audit"
+	"gitlab.com/hyperd/titanic/auth"
+	"google.golang.org/grpc/metadata"
+)
+
+const testSecret = "s3cr3t"
+
+// signToken returns a token of the claims, signed with HS256 by testSecret.
+func signToken(claims string) string {
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(signed))
+	return signed + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticateActor(t *testing.T) {
+	verifier, err := auth.NewVerifier([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticateCall := authenticate(&auth.Authenticator{Tokens: verifier})
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"anonymous", "", "mallory"},
+		{"subject", signToken(`{"sub":"alice","roles":["editor"]}`), "alice"},
+		{"no subject", signToken(`{"roles":["editor"]}`), auth.UnnamedActor},
+	} {
+		md := metadata.Pairs("x-actor", "mallory")
+		if tc.token != "" {
+			md.Set("authorization", "Bearer "+tc.token)
+		}
+		ctx := authenticateCall(recordOrigin(context.Background(), md), md)
+		if got := audit.FromContext(ctx).Actor; got != tc.want {
+			t.Errorf("%s: actor: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"gitlab.com/hyperd/titanic/audit"
+)
+
+// The headers identifying the origin of the changes made by a request. The
+// request ID is generated when missing, and echoed in the response.
+const (
+	actorHeader     = "X-Actor"
+	requestIDHeader = "X-Request-ID"
+)
+
+// recordOrigin attaches the audit.Origin of each routed request to its
+// context, so that the repositories attribute the changes it makes.
+func recordOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o := audit.Origin{
+			Actor:     r.Header.Get(actorHeader),
+			RequestID: r.Header.Get(requestIDHeader),
+		}
+		if o.RequestID == "" {
+			o.RequestID = uuid.New().String()
+		}
+		w.Header().Set(requestIDHeader, o.RequestID)
+		next.ServeHTTP(w, r.WithContext(audit.NewContext(r.Context(), o)))
+	})
+}
+
+func injectOrigin(ctx context.Context, r *http.Request) context.Context {
+	o := audit.FromContext(ctx)
+	if o.Actor != "" {
+		r.Header.Set(actorHeader, o.Actor)
+	}
+	if o.RequestID != "" {
+		r.Header.Set(requestIDHeader, o.RequestID)
+	}
+	return ctx
+}
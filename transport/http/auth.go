@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/audit"
+	"gitlab.com/hyperd/titanic/auth"
+)
+
+// apiKeyHeader carries the API key of the callers which don't present a
+// bearer token.
+const apiKeyHeader = "X-API-Key"
+
+// authenticate verifies the bearer token, or else the API key, of each routed
+// request, if any, rejecting the request when invalid. The changes made by
+// the authenticated requests are attributed to the subject of their
+// credentials, in place of their X-Actor header.
+func authenticate(a *auth.Authenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := auth.BearerToken(r.Header.Get("Authorization"))
+			key := r.Header.Get(apiKeyHeader)
+			if token == "" && key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := a.Authenticate(r.Context(), token, key)
+			claims, err := auth.FromContext(ctx)
+			if err != nil {
+				encodeError(ctx, titanic.ErrUnauthenticated, w)
+				return
+			}
+			o := audit.FromContext(ctx)
+			o.Actor = claims.Actor()
+			ctx = audit.NewContext(ctx, o)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requireAuthentication rejects the anonymous requests, let through by
+// authenticate, to the routes which aren't open to everyone.
+func requireAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := auth.FromContext(r.Context()); err != nil {
+			encodeError(r.Context(), titanic.ErrUnauthenticated, w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitlab.com/hyperd/titanic/audit"
+	"gitlab.com/hyperd/titanic/auth"
+)
+
+const testSecret = "s3cr3t"
+
+// signToken returns a token of the claims, signed with HS256 by testSecret.
+func signToken(claims string) string {
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(signed))
+	return signed + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticateActor(t *testing.T) {
+	verifier, err := auth.NewVerifier([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actor string
+	h := recordOrigin(authenticate(&auth.Authenticator{Tokens: verifier})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = audit.FromContext(r.Context()).Actor
+	})))
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"anonymous", "", "mallory"},
+		{"subject", signToken(`{"sub":"alice","roles":["editor"]}`), "alice"},
+		{"no subject", signToken(`{"roles":["editor"]}`), auth.UnnamedActor},
+	} {
+		actor = ""
+		r := httptest.NewRequest("PATCH", "/people/35d4ab59-fa9d-478d-a57e-61b526ee0a33", nil)
+		r.Header.Set(actorHeader, "mallory")
+		if tc.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		if actor != tc.want {
+			t.Errorf("%s: actor: got %q, want %q", tc.name, actor, tc.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/idempotency"
+	"gitlab.com/hyperd/titanic/importer"
+	"gitlab.com/hyperd/titanic/ratelimit"
+	"gitlab.com/hyperd/titanic/tracing"
+	"gitlab.com/hyperd/titanic/transport"
+)
+
+// knownErrors are the errors mapped back from the codes of the error
+// responses, so that clients can compare them with the titanic errors.
+var knownErrors = []error{
+	titanic.ErrInconsistentIDs,
+	titanic.ErrAlreadyExists,
+	titanic.ErrNotFound,
+	titanic.ErrCmdRepository,
+	titanic.ErrQueryRepository,
+	titanic.ErrInvalidQuery,
+	titanic.ErrVersionConflict,
+	titanic.ErrUnauthenticated,
+	titanic.ErrForbidden,
+	titanic.ErrCanceled,
+	titanic.ErrTimeout,
+	importer.ErrHeader,
+	ratelimit.ErrLimited,
+	idempotency.ErrInvalidKey,
+	idempotency.ErrInFlight,
+	idempotency.ErrMismatch,
+	ErrUnsupportedMediaType,
+	ErrRequestTooLarge,
+}
+
+// NewHTTPClient returns a titanic.Service backed by the HTTP server listening
+// at baseURL, e.g. "https://titanic-api.hyperd.sh:8443". The business errors
+// returned by the server are mapped back to the titanic errors, e.g.
+// titanic.ErrNotFound, while transport errors are returned as they are. The
+// span carried by the context of each call, if any, is propagated to the
+// server, along with the audit.Origin of the changes, and the idempotency
+// key of PostPeople, if any.
+func NewHTTPClient(baseURL string, options ...kithttp.ClientOption) (titanic.Service, error) {
+	if !strings.HasPrefix(baseURL, "http") {
+		baseURL = "http://" + baseURL
+	}
+	tgt, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	tgt.Path = ""
+
+	options = append([]kithttp.ClientOption{kithttp.ClientBefore(injectTraceparent, injectOrigin, injectIdempotencyKey)}, options...)
+
+	// The exported passengers, and the watched events, are read while the
+	// caller consumes the stream, after the endpoint returned: the response
+	// body must be left open.
+	streamOptions := append([]kithttp.ClientOption{kithttp.BufferedStream(true)}, options...)
+
+	return transport.Endpoints{
+		PostPeopleEndpoint:       kithttp.NewClient("POST", tgt, encodePostPeopleRequest, decodePostPeopleResponse, options...).Endpoint(),
+		PostPeopleBatchEndpoint:  kithttp.NewClient("POST", tgt, encodePostPeopleBatchRequest, decodePostPeopleBatchResponse, options...).Endpoint(),
+		GetPeopleByIDEndpoint:    kithttp.NewClient("GET", tgt, encodeGetPeopleByIDRequest, decodeGetPeopleByIDResponse, options...).Endpoint(),
+		PutPeopleEndpoint:        kithttp.NewClient("PUT", tgt, encodePutPeopleRequest, decodePutPeopleResponse, options...).Endpoint(),
+		PatchPeopleEndpoint:      kithttp.NewClient("PATCH", tgt, encodePatchPeopleRequest, decodePatchPeopleResponse, options...).Endpoint(),
+		DeletePeopleEndpoint:     kithttp.NewClient("DELETE", tgt, encodeDeletePeopleRequest, decodeDeletePeopleResponse, options...).Endpoint(),
+		RestorePeopleEndpoint:    kithttp.NewClient("POST", tgt, encodeRestorePeopleRequest, decodeRestorePeopleResponse, options...).Endpoint(),
+		GetPeopleHistoryEndpoint: kithttp.NewClient("GET", tgt, encodeGetPeopleHistoryRequest, decodeGetPeopleHistoryResponse, options...).Endpoint(),
+		GetPeopleEndpoint:        kithttp.NewClient("GET", tgt, encodeGetPeopleRequest, decodeGetPeopleResponse, options...).Endpoint(),
+		ExportPeopleEndpoint:     kithttp.NewClient("GET", tgt, encodeExportPeopleRequest, decodeExportPeopleResponse, streamOptions...).Endpoint(),
+		WatchPeopleEndpoint:      kithttp.NewClient("GET", tgt, encodeWatchPeopleRequest, decodeWatchPeopleResponse, streamOptions...).Endpoint(),
+		GetStatisticsEndpoint:    kithttp.NewClient("GET", tgt, encodeGetStatisticsRequest, decodeGetStatisticsResponse, options...).Endpoint(),
+		GetAPIStatusEndpoint:     kithttp.NewClient("GET", tgt, encodeGetAPIStatusRequest, decodeGetAPIStatusResponse, options...).Endpoint(),
+	}, nil
+}
+
+func injectTraceparent(ctx context.Context, r *http.Request) context.Context {
+	tracing.Inject(ctx, r.Header)
+	return ctx
+}
+
+// errorFrom returns the error carried by an error response, as encoded by
+// encodeError, or nil when the request succeeded. The problems without
+// detail are mapped back to the known errors of their code, while the others
+// are returned as a *titanic.Error, or a *titanic.ValidationError.
+func errorFrom(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var p problem
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil || p.Code == "" {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	if p.Code == titanic.CodeValidationFailed {
+		return &titanic.ValidationError{Fields: p.Fields}
+	}
+	if p.Detail == "" {
+		for _, err := range knownErrors {
+			if titanic.ErrorCode(err) == p.Code {
+				return err
+			}
+		}
+	}
+	return &titanic.Error{Code: p.Code, Title: p.Title, Detail: p.Detail}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/transport"
+)
+
+// formatETag returns the strong entity tag of the given passenger version.
+func formatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch returns the passenger version required by the If-Match header of
+// the request, or 0 when there is none, or titanic.AnyVersion when it is "*",
+// which only requires the passenger to exist. A tag that can't match any
+// version, e.g. a weak one, fails the precondition.
+func ifMatch(r *http.Request) (int, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch v {
+	case "":
+		return 0, nil
+	case "*":
+		return titanic.AnyVersion, nil
+	}
+	if len(v) < 3 || v[0] != '"' || v[len(v)-1] != '"' {
+		return 0, titanic.ErrVersionConflict
+	}
+	version, err := strconv.Atoi(v[1 : len(v)-1])
+	if err != nil || version < 1 {
+		return 0, titanic.ErrVersionConflict
+	}
+	return version, nil
+}
+
+// encodeGetPeopleByIDResponse tags the passenger with its version, to be
+// passed back as If-Match when updating it.
+func encodeGetPeopleByIDResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	if r, ok := response.(transport.GetPeopleByIDResponse); ok && r.Err == nil && r.People.Version != 0 {
+		w.Header().Set("ETag", formatETag(r.People.Version))
+	}
+	return encodeResponse(ctx, w, response)
+}
+
+// matchVersion requires the passenger to be updated at the If-Match version,
+// if any. The version of the body is ignored, If-Match being the only
+// precondition: a client echoing the passenger it read doesn't ask for it.
+func matchVersion(r *http.Request, people *titanic.People) error {
+	version, err := ifMatch(r)
+	if err != nil {
+		return err
+	}
+	people.Version = version
+	return nil
+}
+
+// setIfMatch sets the If-Match header of an outgoing request to the given
+// version, if any.
+func setIfMatch(req *http.Request, version int) {
+	switch version {
+	case 0:
+	case titanic.AnyVersion:
+		req.Header.Set("If-Match", "*")
+	default:
+		req.Header.Set("If-Match", formatETag(version))
+	}
+}
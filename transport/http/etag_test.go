@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"gitlab.com/hyperd/titanic"
+)
+
+func TestMatchVersion(t *testing.T) {
+	for _, tc := range []struct {
+		ifMatch string
+		want    int
+		err     error
+	}{
+		// The version of the body, echoed by the client, is no precondition.
+		{"", 0, nil},
+		{`"2"`, 2, nil},
+		{"*", titanic.AnyVersion, nil},
+		{`W/"2"`, 0, titanic.ErrVersionConflict},
+		{`"0"`, 0, titanic.ErrVersionConflict},
+	} {
+		r := httptest.NewRequest("PUT", "/people/35d4ab59-fa9d-478d-a57e-61b526ee0a33", nil)
+		if tc.ifMatch != "" {
+			r.Header.Set("If-Match", tc.ifMatch)
+		}
+		people := titanic.People{Name: "Amy", Version: 3}
+		err := matchVersion(r, &people)
+		if err != tc.err {
+			t.Errorf("If-Match %s: got %v, want %v", tc.ifMatch, err, tc.err)
+			continue
+		}
+		if err == nil && people.Version != tc.want {
+			t.Errorf("If-Match %s: version: got %d, want %d", tc.ifMatch, people.Version, tc.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/transport"
+)
+
+const (
+	mediaTypeEventStream = "text/event-stream"
+
+	// lastEventIDHeader is set by the EventSource clients when they
+	// reconnect, to the id of the last event they received.
+	lastEventIDHeader = "Last-Event-ID"
+)
+
+// keepAliveInterval is the interval at which a comment is sent to the idle
+// watchers, so that neither them nor the proxies in between time out.
+const keepAliveInterval = 15 * time.Second
+
+func decodeWatchPeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
+	var req transport.WatchPeopleRequest
+	if v := strings.TrimSpace(r.Header.Get(lastEventIDHeader)); v != "" {
+		if req.LastEventID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, titanic.ErrInvalidQuery
+		}
+	}
+	return req, nil
+}
+
+// encodeWatchPeopleResponse streams the events to the client as Server-Sent
+// Events, until the client goes away or falls behind; in the latter case the
+// stream ends, and the client resumes it from the last event it received.
+func encodeWatchPeopleResponse(logger log.Logger) kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		if e, ok := response.(errorer); ok && e.Failed() != nil {
+			// Not a Go kit transport error, but a business-logic error.
+			// Provide those as HTTP errors.
+			encodeError(ctx, e.Failed(), w)
+			return nil
+		}
+		resp := response.(transport.WatchPeopleResponse)
+
+		flush := func() {
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+
+		setSecurityHeaders(w)
+		w.Header().Set("Content-Type", mediaTypeEventStream)
+		w.Header().Set("Cache-Control", "no-cache")
+		// Disable the buffering of the reverse proxies, e.g. nginx.
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flush()
+
+		// The keep-alives and the events are written by different
+		// goroutines.
+		var mtx sync.Mutex
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			ticker := time.NewTicker(keepAliveInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					mtx.Lock()
+					fmt.Fprint(w, ": keep-alive\n\n")
+					flush()
+					mtx.Unlock()
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		err := resp.Stream(func(e titanic.Event) error {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			mtx.Lock()
+			defer mtx.Unlock()
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data); err != nil {
+				return err
+			}
+			flush()
+			return nil
+		})
+		close(done)
+		<-stopped
+
+		if err != nil {
+			logger.Log("method", "WatchPeople", "err", err)
+		}
+		return nil
+	}
+}
+
+func encodeWatchPeopleRequest(_ context.Context, req *http.Request, request interface{}) error {
+	// r.Methods("GET").Path("/people/events")
+	r := request.(transport.WatchPeopleRequest)
+	req.URL.Path = "/people/events"
+	req.Header.Set("Accept", mediaTypeEventStream)
+	if r.LastEventID != 0 {
+		req.Header.Set(lastEventIDHeader, strconv.FormatUint(r.LastEventID, 10))
+	}
+	return nil
+}
+
+// decodeWatchPeopleResponse returns a response streaming the events out of
+// the Server-Sent Events body, which is closed once the stream ends.
+func decodeWatchPeopleResponse(ctx context.Context, resp *http.Response) (interface{}, error) {
+	var response transport.WatchPeopleResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		resp.Body.Close()
+		return response, nil
+	}
+
+	response.Stream = func(fn func(titanic.Event) error) error {
+		defer resp.Body.Close()
+
+		var data bytes.Buffer
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				// A blank line dispatches the event; only its data
+				// is needed, as it carries the whole event.
+				if data.Len() == 0 {
+					continue
+				}
+				var e titanic.Event
+				if err := json.Unmarshal(data.Bytes(), &e); err != nil {
+					return err
+				}
+				data.Reset()
+				if err := fn(e); err != nil {
+					return err
+				}
+			case strings.HasPrefix(line, "data:"):
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			return err
+		}
+		return nil
+	}
+	return response, nil
+}
@@ -0,0 +1,296 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/importer"
+	"gitlab.com/hyperd/titanic/transport"
+)
+
+// Media types served by GET /people/.
+const (
+	mediaTypeJSON   = "application/json"
+	mediaTypeCSV    = "text/csv"
+	mediaTypeNDJSON = "application/x-ndjson"
+)
+
+// flushEvery is the number of exported passengers after which the
+// response is flushed to the client.
+const flushEvery = 100
+
+// acceptsExport matches the requests preferring one of the export formats
+// over JSON.
+func acceptsExport(r *http.Request, _ *mux.RouteMatch) bool {
+	switch negotiate(r.Header.Get("Accept"), mediaTypeJSON, mediaTypeCSV, mediaTypeNDJSON) {
+	case mediaTypeCSV, mediaTypeNDJSON:
+		return true
+	}
+	return false
+}
+
+// negotiate returns the offer preferred by the Accept header, the first one
+// when the header is missing, or "" when none is acceptable.
+func negotiate(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	var (
+		best         string
+		bestQ        float64
+		bestSpecific int
+	)
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		for _, offer := range offers {
+			specific := matchMediaRange(mediaRange, offer)
+			if specific < 0 || q == 0 {
+				continue
+			}
+			if q > bestQ || (q == bestQ && specific > bestSpecific) {
+				best, bestQ, bestSpecific = offer, q, specific
+			}
+		}
+	}
+	return best
+}
+
+// matchMediaRange returns how specifically the media range matches the media
+// type: 2 for an exact match, 1 for type/*, 0 for */*, -1 for no match.
+func matchMediaRange(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+	return -1
+}
+
+func decodeExportPeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
+	req := transport.ExportPeopleRequest{
+		Format: negotiate(r.Header.Get("Accept"), mediaTypeJSON, mediaTypeCSV, mediaTypeNDJSON),
+	}
+	q := r.URL.Query()
+
+	if req.Sort, err = titanic.ParseSort(q.Get("sort")); err != nil {
+		return nil, err
+	}
+
+	if req.IncludeDeleted, err = parseBoolParam(q, "include_deleted"); err != nil {
+		return nil, err
+	}
+
+	if req.Filter, err = decodePeopleFilter(q); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// encodeExportResponse streams the passengers to the client, as CSV laid out
+// as data/titanic.csv, or as newline delimited JSON. Errors are reported
+// as usual until the first passenger is written; past that point the
+// response is aborted, so that the client doesn't take it for complete.
+func encodeExportResponse(logger log.Logger) kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		if e, ok := response.(errorer); ok && e.Failed() != nil {
+			// Not a Go kit transport error, but a business-logic error.
+			// Provide those as HTTP errors.
+			encodeError(ctx, e.Failed(), w)
+			return nil
+		}
+		resp := response.(transport.ExportPeopleResponse)
+
+		var enc peopleEncoder
+		if resp.Format == mediaTypeCSV {
+			enc = &csvEncoder{w: csv.NewWriter(w)}
+			w.Header().Set("Content-Disposition", `attachment; filename="titanic.csv"`)
+		} else {
+			enc = &ndjsonEncoder{enc: json.NewEncoder(w)}
+		}
+
+		var count int
+		started := false
+		start := func() error {
+			started = true
+			setSecurityHeaders(w)
+			w.Header().Set("Content-Type", resp.Format+"; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			return enc.begin()
+		}
+
+		err := resp.Stream(func(p titanic.People) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+			if err := enc.encode(p); err != nil {
+				return err
+			}
+			if count++; count%flushEvery == 0 {
+				return flush(w, enc)
+			}
+			return nil
+		})
+
+		if err != nil && !started {
+			encodeError(ctx, err, w)
+			return nil
+		}
+		if err == nil && !started {
+			err = start()
+		}
+		if err == nil {
+			err = flush(w, enc)
+		}
+		if err != nil {
+			logger.Log("method", "ExportPeople", "exported", count, "err", err)
+			panic(http.ErrAbortHandler)
+		}
+		return nil
+	}
+}
+
+func flush(w http.ResponseWriter, enc peopleEncoder) error {
+	if err := enc.flush(); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// peopleEncoder writes passengers one at a time.
+type peopleEncoder interface {
+	begin() error
+	encode(p titanic.People) error
+	flush() error
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) begin() error {
+	return e.w.Write(importer.Header)
+}
+
+func (e *csvEncoder) encode(p titanic.People) error {
+	var survived string
+	if p.Survived != nil {
+		survived = "0"
+		if *p.Survived {
+			survived = "1"
+		}
+	}
+	var fare string
+	if p.Fare != nil {
+		fare = strconv.FormatFloat(float64(*p.Fare), 'f', -1, 32)
+	}
+	return e.w.Write([]string{
+		survived,
+		formatInt(p.Pclass),
+		p.Name,
+		p.Sex,
+		formatInt(p.Age),
+		formatInt(p.SiblingsSpousesAbroad),
+		formatInt(p.ParentsChildrenAboard),
+		fare,
+	})
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) begin() error { return nil }
+
+func (e *ndjsonEncoder) encode(p titanic.People) error {
+	// Encode terminates every value with a newline.
+	return e.enc.Encode(p)
+}
+
+func (e *ndjsonEncoder) flush() error { return nil }
+
+func formatInt(i *int) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.Itoa(*i)
+}
+
+func encodeExportPeopleRequest(_ context.Context, req *http.Request, request interface{}) error {
+	// r.Methods("GET").Path("/people/"), streamed as NDJSON
+	r := request.(transport.ExportPeopleRequest)
+	q := url.Values{}
+	if len(r.Sort) > 0 {
+		q.Set("sort", titanic.FormatSort(r.Sort))
+	}
+	if r.IncludeDeleted {
+		q.Set("include_deleted", "true")
+	}
+	encodePeopleFilter(q, r.Filter)
+	req.URL.Path = "/people/"
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Accept", mediaTypeNDJSON)
+	return nil
+}
+
+// decodeExportPeopleResponse returns a response streaming the passengers out
+// of the NDJSON body, which is closed once the stream ends.
+func decodeExportPeopleResponse(_ context.Context, resp *http.Response) (interface{}, error) {
+	response := transport.ExportPeopleResponse{Format: mediaTypeNDJSON}
+	if response.Err = errorFrom(resp); response.Err != nil {
+		resp.Body.Close()
+		return response, nil
+	}
+
+	response.Stream = func(fn func(titanic.People) error) error {
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var p titanic.People
+			if err := dec.Decode(&p); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+	}
+	return response, nil
+}
@@ -1,3 +1,17 @@
 module gitlab.com/hyperd/titanic/transport/http
 
 go 1.13
+
+require (
+	github.com/go-kit/kit v0.9.0
+	github.com/google/uuid v1.1.1
+	github.com/gorilla/mux v1.7.4
+	github.com/prometheus/client_golang v1.12.2
+	gitlab.com/hyperd/titanic v0.0.0-00010101000000-000000000000
+	gitlab.com/hyperd/titanic/transport v0.0.0-00010101000000-000000000000
+)
+
+replace (
+	gitlab.com/hyperd/titanic => ../..
+	gitlab.com/hyperd/titanic/transport => ../../transport
+)
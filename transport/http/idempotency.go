@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+
+	"gitlab.com/hyperd/titanic/auth"
+	"gitlab.com/hyperd/titanic/idempotency"
+)
+
+// idempotencyKeyHeader carries the idempotency key chosen by the client for
+// a request, and its retries.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotentBodySize is the size of the largest body of the requests
+// carrying an idempotency key, which is read whole to be fingerprinted.
+const maxIdempotentBodySize = 1 << 20
+
+// idempotent makes the handler honor the Idempotency-Key header: the
+// response to the first request carrying a key is kept by the store, and
+// replayed to the retries of the request, which aren't handled again. The
+// keys are scoped to the authenticated caller, if any.
+//
+// The responses to the requests which weren't executed, failing to be
+// authenticated, authorized or rate limited, aren't kept: the key is
+// released, so that the request can be retried. Any other response is kept,
+// including the server errors, e.g. a timeout, as the request may have been
+// executed nonetheless.
+func idempotent(store idempotency.Store, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > idempotency.MaxKeyLength {
+			encodeError(ctx, idempotency.ErrInvalidKey, w)
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			if len(body) == maxIdempotentBodySize {
+				err = ErrRequestTooLarge
+			}
+			encodeError(ctx, err, w)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		key = scopeIdempotencyKey(ctx, key)
+		fingerprint := fingerprintRequest(r, body)
+		record, reserved, err := store.Reserve(key, fingerprint)
+		switch {
+		case err != nil:
+			encodeError(ctx, err, w)
+			return
+		case !reserved && record.Fingerprint != fingerprint:
+			encodeError(ctx, idempotency.ErrMismatch, w)
+			return
+		case !reserved && record.Response == nil:
+			encodeError(ctx, idempotency.ErrInFlight, w)
+			return
+		case !reserved:
+			resp := record.Response
+			if resp.ContentType != "" {
+				w.Header().Set("Content-Type", resp.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(resp.Status)
+			w.Write(resp.Body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		kept := false
+		defer func() {
+			if !kept {
+				store.Release(key)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+
+		switch rec.status {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+			return
+		}
+		kept = store.Complete(key, idempotency.Response{
+			Status:      rec.status,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		}) == nil
+	})
+}
+
+// scopeIdempotencyKey prefixes the key with the subject of the caller, so
+// that different callers can't replay the responses to each other.
+func scopeIdempotencyKey(ctx context.Context, key string) string {
+	if claims, err := auth.FromContext(ctx); err == nil {
+		return claims.Subject + "\x00" + key
+	}
+	return "\x00" + key
+}
+
+// fingerprintRequest returns the fingerprint of the request, telling apart
+// the different requests reusing the same key.
+func fingerprintRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder records the status code and the body of the response,
+// as they are written.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func injectIdempotencyKey(ctx context.Context, r *http.Request) context.Context {
+	if key := idempotency.FromContext(ctx); key != "" {
+		r.Header.Set(idempotencyKeyHeader, key)
+	}
+	return ctx
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/audit"
+	"gitlab.com/hyperd/titanic/idempotency"
+	"gitlab.com/hyperd/titanic/importer"
+	"gitlab.com/hyperd/titanic/ratelimit"
+)
+
+// Codes of the errors of the HTTP transport.
+const (
+	// CodeUnsupportedMediaType is the code of ErrUnsupportedMediaType.
+	CodeUnsupportedMediaType titanic.Code = "unsupported_media_type"
+	// CodeRequestTooLarge is the code of ErrRequestTooLarge.
+	CodeRequestTooLarge titanic.Code = "request_too_large"
+)
+
+// statusClientClosedRequest is the non-standard status of the requests
+// canceled by the client, as logged by nginx; the client doesn't get it.
+const statusClientClosedRequest = 499
+
+// problemContentType is the media type of the error responses, as defined
+// by RFC 7807.
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code of an error to make the type of its
+// problem.
+const problemTypePrefix = "urn:titanic:problem:"
+
+// statuses are the HTTP status codes of the error codes; any other code is
+// answered with 500 Internal Server Error.
+var statuses = map[titanic.Code]int{
+	titanic.CodeNotFound:         http.StatusNotFound,
+	titanic.CodeAlreadyExists:    http.StatusConflict,
+	titanic.CodeInconsistentIDs:  http.StatusBadRequest,
+	titanic.CodeInvalidQuery:     http.StatusBadRequest,
+	titanic.CodeMalformedRequest: http.StatusBadRequest,
+	titanic.CodeValidationFailed: http.StatusUnprocessableEntity,
+	titanic.CodeVersionConflict:  http.StatusPreconditionFailed,
+	titanic.CodeUnauthenticated:  http.StatusUnauthorized,
+	titanic.CodeForbidden:        http.StatusForbidden,
+	titanic.CodeRepositoryWrite:  http.StatusServiceUnavailable,
+	titanic.CodeRepositoryRead:   http.StatusServiceUnavailable,
+	titanic.CodeCanceled:         statusClientClosedRequest,
+	titanic.CodeTimeout:          http.StatusGatewayTimeout,
+	ratelimit.CodeRateLimited:    http.StatusTooManyRequests,
+	idempotency.CodeInvalidKey:   http.StatusBadRequest,
+	idempotency.CodeInFlight:     http.StatusConflict,
+	idempotency.CodeMismatch:     http.StatusUnprocessableEntity,
+	importer.CodeHeader:          http.StatusBadRequest,
+	CodeUnsupportedMediaType:     http.StatusUnsupportedMediaType,
+	CodeRequestTooLarge:          http.StatusRequestEntityTooLarge,
+}
+
+// problem is the body of the error responses: an RFC 7807 problem details
+// object, extended with the code of the error, the ID of the request and,
+// for the validation errors, the problems of each field.
+type problem struct {
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Status    int                  `json:"status"`
+	Detail    string               `json:"detail,omitempty"`
+	Instance  string               `json:"instance,omitempty"`
+	Code      titanic.Code         `json:"code"`
+	RequestID string               `json:"request_id,omitempty"`
+	Fields    []titanic.FieldError `json:"fields,omitempty"`
+}
+
+// problemFrom describes the error as a problem. The errors without a code
+// are described as internal errors, without detail, so that the failures of
+// the dependencies, e.g. the database, aren't disclosed to the clients.
+func problemFrom(ctx context.Context, err error) problem {
+	p := problem{
+		Code:      titanic.ErrorCode(err),
+		RequestID: audit.FromContext(ctx).RequestID,
+	}
+	p.Type = problemTypePrefix + string(p.Code)
+	p.Instance, _ = ctx.Value(kithttp.ContextKeyRequestPath).(string)
+	p.Status = statusFrom(p.Code)
+
+	var (
+		e    *titanic.Error
+		verr *titanic.ValidationError
+	)
+	switch {
+	case errors.As(err, &e):
+		p.Title, p.Detail = e.Title, e.Detail
+	case errors.As(err, &verr):
+		p.Title = "invalid people"
+		p.Fields = verr.Fields
+		details := make([]string, len(verr.Fields))
+		for i, f := range verr.Fields {
+			details[i] = f.Error()
+		}
+		p.Detail = strings.Join(details, "; ")
+	default:
+		p.Title = "internal error"
+	}
+	return p
+}
+
+func statusFrom(code titanic.Code) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+func encodeError(ctx context.Context, err error, w http.ResponseWriter) {
+	if err == nil {
+		panic("encodeError with nil error")
+	}
+	if err == titanic.ErrUnauthenticated {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	setRateLimitHeaders(ctx, w)
+	p := problemFrom(ctx, err)
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// populateRequestContext attaches the details of each routed request to its
+// context, e.g. the path telling the instance of the problems.
+func populateRequestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(kithttp.PopulateRequestContext(r.Context(), r)))
+	})
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+	"gitlab.com/hyperd/titanic/ratelimit"
+)
+
+// limitClient attaches the address of the client to the context, so that
+// the anonymous requests are limited by address. X-Forwarded-For is only
+// honoured behind the proxies trusted by the limiter, e.g. the ingress: the
+// address is otherwise the remote one, as the header is set by the client.
+func limitClient(l *ratelimit.Limiter) kithttp.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return ratelimit.NewContext(ctx, l.ClientAddr(r.RemoteAddr, r.Header.Get("X-Forwarded-For")))
+	}
+}
+
+// setRateLimitHeaders reports the quota the client is left with, if its
+// request was limited.
+func setRateLimitHeaders(ctx context.Context, w http.ResponseWriter) context.Context {
+	q, ok := ratelimit.QuotaFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(q.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(q.Remaining))
+	w.Header().Set("X-RateLimit-Reset", formatSeconds(q.Reset))
+	if q.RetryAfter > 0 {
+		w.Header().Set("Retry-After", formatSeconds(q.RetryAfter))
+	}
+	return ctx
+}
+
+// formatSeconds formats the duration in whole seconds, rounded up.
+func formatSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -7,16 +7,24 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/go-kit/kit/log"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"gitlab.com/hyperd/titanic"
+	"gitlab.com/hyperd/titanic/auth"
+	"gitlab.com/hyperd/titanic/idempotency"
+	"gitlab.com/hyperd/titanic/ratelimit"
+	"gitlab.com/hyperd/titanic/tracing"
 	"gitlab.com/hyperd/titanic/transport"
 )
 
@@ -24,35 +32,101 @@ var (
 	// ErrBadRouting is returned when an expected path variable is missing.
 	// It always indicates programmer error.
 	ErrBadRouting = errors.New("inconsistent mapping between route and handler (programmer error)")
+
+	// ErrUnsupportedMediaType is returned when the request body is not in the
+	// format expected by the endpoint.
+	ErrUnsupportedMediaType = titanic.NewError(CodeUnsupportedMediaType, "unsupported media type")
+
+	// ErrRequestTooLarge is returned when the request body exceeds the size
+	// accepted by the endpoint.
+	ErrRequestTooLarge = titanic.NewError(CodeRequestTooLarge, "request body too large")
 )
 
 // MakeHTTPHandler mounts all of the service endpoints into an http.Handler.
-func MakeHTTPHandler(s titanic.Service, logger log.Logger) http.Handler {
+// Each request is traced by the given tracer, unless nil, and the changes it
+// makes are attributed to the X-Actor and X-Request-ID headers. The bearer
+// tokens and the API keys are verified by the given authenticator, unless
+// nil, in which case they are ignored. The requests of each client are rate
+// limited by the given limiter, unless nil. The responses to the requests
+// carrying an Idempotency-Key are kept by the given store, unless nil. With an
+// authenticator, the metrics are only exposed to the authenticated callers.
+func MakeHTTPHandler(s titanic.Service, logger log.Logger, tracer *tracing.Tracer, authenticator *auth.Authenticator, limiter *ratelimit.Limiter, idempotencyKeys idempotency.Store) http.Handler {
 	r := mux.NewRouter()
+	r.Use(populateRequestContext)
+	if tracer != nil {
+		r.Use(traceRequests(tracer))
+	}
+	r.Use(recordOrigin)
+	if authenticator != nil {
+		r.Use(authenticate(authenticator))
+	}
 	e := transport.MakeServerEndpoints(s)
 	options := []kithttp.ServerOption{
 		kithttp.ServerErrorLogger(logger),
 		kithttp.ServerErrorEncoder(encodeError),
 	}
+	if limiter != nil {
+		e = transport.RateLimit(e, limiter)
+		options = append(options,
+			kithttp.ServerBefore(limitClient(limiter)),
+			kithttp.ServerAfter(setRateLimitHeaders),
+		)
+	}
 
-	// POST    /people/                       	   adds another passenger to the people collection
-	// GET     /people/:uuid                       retrieves the given passenger by uuid from the people collection
-	// PUT     /people/:uuid                       post updated information about a passenger (uuid)
-	// PATCH   /people/:uuid                       partial update of the passenger information
-	// DELETE  /people/:uuid                       removes the given passenger
-	// GET     /people/           				   retrieves all the passengers from the people collection
+	// POST    /people/                       	   adds another passenger to the people collection, once per Idempotency-Key
+	// POST    /people/batch                       adds several passengers at once, all or none of them
+	// POST    /people/import                      imports the passengers from a CSV file (text/csv)
+	// GET     /people/:uuid                       retrieves the given passenger by uuid from the people collection, tagged with its version (ETag)
+	// PUT     /people/:uuid                       post updated information about a passenger (uuid), provided it is at the If-Match version
+	// PATCH   /people/:uuid                       partial update of the passenger information, provided it is at the If-Match version
+	// DELETE  /people/:uuid                       removes the given passenger, provided it is at the If-Match version, until purged
+	// POST    /people/:uuid/restore               restores the given removed passenger
+	// GET     /people/:uuid/history               retrieves the changes made to the given passenger, oldest first
+	// GET     /people/           				   retrieves a page of passengers from the people collection, along with the removed ones if include_deleted
+	// GET     /people/ (Accept: text/csv)         streams the people collection as CSV, or as NDJSON (Accept: application/x-ndjson)
+	// GET     /people/stats                       returns the survival statistics, grouped by pclass, sex and/or age band
+	// GET     /people/events                      streams the changes to the passengers as Server-Sent Events, resumed after the Last-Event-ID
+	// GET     /metrics                            exposes the service metrics to Prometheus, authenticated if the credentials are verified
 	// GET     /           						   returns the API status
 
-	r.Methods("POST").Path("/people/").Handler(kithttp.NewServer(
+	var postPeople http.Handler = kithttp.NewServer(
 		e.PostPeopleEndpoint,
 		decodePostPeopleRequest,
 		encodeResponse,
 		options...,
+	)
+	if idempotencyKeys != nil {
+		postPeople = idempotent(idempotencyKeys, postPeople)
+	}
+	r.Methods("POST").Path("/people/").Handler(postPeople)
+	r.Methods("POST").Path("/people/batch").Handler(kithttp.NewServer(
+		e.PostPeopleBatchEndpoint,
+		decodePostPeopleBatchRequest,
+		encodeResponse,
+		options...,
+	))
+	r.Methods("POST").Path("/people/import").Handler(kithttp.NewServer(
+		e.ImportPeopleEndpoint,
+		decodeImportPeopleRequest,
+		encodeResponse,
+		options...,
+	))
+	r.Methods("GET").Path("/people/stats").Handler(kithttp.NewServer(
+		e.GetStatisticsEndpoint,
+		decodeGetStatisticsRequest,
+		encodeResponse,
+		options...,
+	))
+	r.Methods("GET").Path("/people/events").Handler(kithttp.NewServer(
+		e.WatchPeopleEndpoint,
+		decodeWatchPeopleRequest,
+		encodeWatchPeopleResponse(logger),
+		options...,
 	))
 	r.Methods("GET").Path("/people/{uuid}").Handler(kithttp.NewServer(
 		e.GetPeopleByIDEndpoint,
 		decodeGetPeopleByIDRequest,
-		encodeResponse,
+		encodeGetPeopleByIDResponse,
 		options...,
 	))
 	r.Methods("PUT").Path("/people/{uuid}").Handler(kithttp.NewServer(
@@ -73,12 +147,35 @@ func MakeHTTPHandler(s titanic.Service, logger log.Logger) http.Handler {
 		encodeResponse,
 		options...,
 	))
+	r.Methods("POST").Path("/people/{uuid}/restore").Handler(kithttp.NewServer(
+		e.RestorePeopleEndpoint,
+		decodeRestorePeopleRequest,
+		encodeResponse,
+		options...,
+	))
+	r.Methods("GET").Path("/people/{uuid}/history").Handler(kithttp.NewServer(
+		e.GetPeopleHistoryEndpoint,
+		decodeGetPeopleHistoryRequest,
+		encodeResponse,
+		options...,
+	))
+	r.Methods("GET").Path("/people/").MatcherFunc(acceptsExport).Handler(kithttp.NewServer(
+		e.ExportPeopleEndpoint,
+		decodeExportPeopleRequest,
+		encodeExportResponse(logger),
+		options...,
+	))
 	r.Methods("GET").Path("/people/").Handler(kithttp.NewServer(
 		e.GetPeopleEndpoint,
 		decodeGetPeopleRequest,
 		encodeResponse,
 		options...,
 	))
+	var metrics http.Handler = promhttp.Handler()
+	if authenticator != nil {
+		metrics = requireAuthentication(metrics)
+	}
+	r.Methods("GET").Path("/metrics").Handler(metrics)
 	r.Methods("GET").Path("/").Handler(kithttp.NewServer(
 		e.GetAPIStatusEndpoint,
 		decodeGetAPIStatusRequest,
@@ -88,35 +185,68 @@ func MakeHTTPHandler(s titanic.Service, logger log.Logger) http.Handler {
 	return r
 }
 
+// malformed returns the error of a request which can't be decoded.
+func malformed(err error) error {
+	return titanic.ErrMalformedRequest.WithDetail(err.Error())
+}
+
+// uuidVar parses the uuid path variable of the request.
+func uuidVar(r *http.Request) (uuid.UUID, error) {
+	v, ok := mux.Vars(r)["uuid"]
+	if !ok {
+		return uuid.Nil, ErrBadRouting
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil, malformed(err)
+	}
+	return id, nil
+}
+
 func decodePostPeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
 	var req transport.PostPeopleRequest
 	if e := json.NewDecoder(r.Body).Decode(&req.People); e != nil {
-		return nil, e
+		return nil, malformed(e)
 	}
 	return req, nil
 }
 
-func decodeGetPeopleByIDRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
-	vars := mux.Vars(r)
-	id, err := uuid.Parse(vars["uuid"])
+func decodePostPeopleBatchRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
+	var req transport.PostPeopleBatchRequest
+	if e := json.NewDecoder(r.Body).Decode(&req.People); e != nil {
+		return nil, malformed(e)
+	}
+	return req, nil
+}
+
+func decodeImportPeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "text/csv" {
+		return nil, ErrUnsupportedMediaType
+	}
+	// The body is read while the endpoint runs, streaming the import.
+	return transport.ImportPeopleRequest{CSV: r.Body}, nil
+}
 
+func decodeGetPeopleByIDRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := uuidVar(r)
 	if err != nil {
-		return nil, ErrBadRouting
+		return nil, err
 	}
 
 	return transport.GetPeopleByIDRequest{ID: id}, nil
 }
 
 func decodePutPeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
-	vars := mux.Vars(r)
-	id, err := uuid.Parse(vars["uuid"])
-
+	id, err := uuidVar(r)
 	if err != nil {
-		return nil, ErrBadRouting
+		return nil, err
 	}
 
 	var people titanic.People
 	if err := json.NewDecoder(r.Body).Decode(&people); err != nil {
+		return nil, malformed(err)
+	}
+	if err := matchVersion(r, &people); err != nil {
 		return nil, err
 	}
 	return transport.PutPeopleRequest{
@@ -126,15 +256,16 @@ func decodePutPeopleRequest(_ context.Context, r *http.Request) (request interfa
 }
 
 func decodePatchPeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
-	vars := mux.Vars(r)
-	id, err := uuid.Parse(vars["uuid"])
-
+	id, err := uuidVar(r)
 	if err != nil {
-		return nil, ErrBadRouting
+		return nil, err
 	}
 
 	var people titanic.People
 	if err := json.NewDecoder(r.Body).Decode(&people); err != nil {
+		return nil, malformed(err)
+	}
+	if err := matchVersion(r, &people); err != nil {
 		return nil, err
 	}
 	return transport.PatchPeopleRequest{
@@ -144,18 +275,152 @@ func decodePatchPeopleRequest(_ context.Context, r *http.Request) (request inter
 }
 
 func decodeDeletePeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
-	vars := mux.Vars(r)
-	id, err := uuid.Parse(vars["uuid"])
+	id, err := uuidVar(r)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.DeletePeopleRequest{ID: id, Version: version}, nil
+}
+
+func decodeRestorePeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := uuidVar(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.RestorePeopleRequest{ID: id}, nil
+}
 
+func decodeGetPeopleHistoryRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
+	id, err := uuidVar(r)
 	if err != nil {
-		return nil, ErrBadRouting
+		return nil, err
 	}
 
-	return transport.DeletePeopleRequest{ID: id}, nil
+	return transport.GetPeopleHistoryRequest{ID: id}, nil
 }
 
 func decodeGetPeopleRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
-	return transport.GetPeopleRequest{}, nil
+	var req transport.GetPeopleRequest
+	q := r.URL.Query()
+
+	if v := q.Get("limit"); v != "" {
+		if req.Limit, err = strconv.Atoi(v); err != nil {
+			return nil, titanic.ErrInvalidQuery
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if req.Offset, err = strconv.Atoi(v); err != nil {
+			return nil, titanic.ErrInvalidQuery
+		}
+	}
+	req.Cursor = q.Get("cursor")
+
+	if req.Sort, err = titanic.ParseSort(q.Get("sort")); err != nil {
+		return nil, err
+	}
+
+	if req.IncludeDeleted, err = parseBoolParam(q, "include_deleted"); err != nil {
+		return nil, err
+	}
+
+	if req.Filter, err = decodePeopleFilter(q); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// decodePeopleFilter parses the people filter from the query string, e.g.
+// ?survived=true&pclass=3&sex=female&age_max=17
+func decodePeopleFilter(q url.Values) (f titanic.PeopleFilter, err error) {
+	if v := q.Get("survived"); v != "" {
+		survived, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, titanic.ErrInvalidQuery
+		}
+		f.Survived = &survived
+	}
+	if f.Pclass, err = parseIntParam(q, "pclass"); err != nil {
+		return f, err
+	}
+	f.Sex = q.Get("sex")
+	if f.AgeMin, err = parseIntParam(q, "age_min"); err != nil {
+		return f, err
+	}
+	if f.AgeMax, err = parseIntParam(q, "age_max"); err != nil {
+		return f, err
+	}
+	if f.FareMin, err = parseFloatParam(q, "fare_min"); err != nil {
+		return f, err
+	}
+	if f.FareMax, err = parseFloatParam(q, "fare_max"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+func parseBoolParam(q url.Values, key string) (bool, error) {
+	v := q.Get(key)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, titanic.ErrInvalidQuery
+	}
+	return b, nil
+}
+
+func parseIntParam(q url.Values, key string) (*int, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, titanic.ErrInvalidQuery
+	}
+	return &i, nil
+}
+
+func parseFloatParam(q url.Values, key string) (*float32, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 32)
+	if err != nil {
+		return nil, titanic.ErrInvalidQuery
+	}
+	f32 := float32(f)
+	return &f32, nil
+}
+
+func decodeGetStatisticsRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
+	var req transport.GetStatisticsRequest
+	q := r.URL.Query()
+
+	if v := q.Get("group_by"); v != "" {
+		req.GroupBy = strings.Split(v, ",")
+	}
+	if v := q.Get("age_band"); v != "" {
+		if req.AgeBandWidth, err = strconv.Atoi(v); err != nil {
+			return nil, titanic.ErrInvalidQuery
+		}
+	}
+
+	if req.Filter, err = decodePeopleFilter(q); err != nil {
+		return nil, err
+	}
+
+	return req, nil
 }
 
 func decodeGetAPIStatusRequest(_ context.Context, r *http.Request) (request interface{}, err error) {
@@ -164,8 +429,16 @@ func decodeGetAPIStatusRequest(_ context.Context, r *http.Request) (request inte
 
 func encodePostPeopleRequest(ctx context.Context, req *http.Request, request interface{}) error {
 	// r.Methods("POST").Path("/people/")
+	r := request.(transport.PostPeopleRequest)
 	req.URL.Path = "/people/"
-	return encodeRequest(ctx, req, request)
+	return encodeRequest(ctx, req, r.People)
+}
+
+func encodePostPeopleBatchRequest(ctx context.Context, req *http.Request, request interface{}) error {
+	// r.Methods("POST").Path("/people/batch")
+	r := request.(transport.PostPeopleBatchRequest)
+	req.URL.Path = "/people/batch"
+	return encodeRequest(ctx, req, r.People)
 }
 
 func encodeGetPeopleByIDRequest(ctx context.Context, req *http.Request, request interface{}) error {
@@ -173,7 +446,7 @@ func encodeGetPeopleByIDRequest(ctx context.Context, req *http.Request, request
 	r := request.(transport.GetPeopleByIDRequest)
 	peopleID := url.QueryEscape(r.ID.String())
 	req.URL.Path = "/people/" + peopleID
-	return encodeRequest(ctx, req, request)
+	return nil
 }
 
 func encodePutPeopleRequest(ctx context.Context, req *http.Request, request interface{}) error {
@@ -181,7 +454,8 @@ func encodePutPeopleRequest(ctx context.Context, req *http.Request, request inte
 	r := request.(transport.PutPeopleRequest)
 	peopleID := url.QueryEscape(r.ID.String())
 	req.URL.Path = "/people/" + peopleID
-	return encodeRequest(ctx, req, request)
+	setIfMatch(req, r.People.Version)
+	return encodeRequest(ctx, req, r.People)
 }
 
 func encodePatchPeopleRequest(ctx context.Context, req *http.Request, request interface{}) error {
@@ -189,7 +463,8 @@ func encodePatchPeopleRequest(ctx context.Context, req *http.Request, request in
 	r := request.(transport.PatchPeopleRequest)
 	peopleID := url.QueryEscape(r.ID.String())
 	req.URL.Path = "/people/" + peopleID
-	return encodeRequest(ctx, req, request)
+	setIfMatch(req, r.People.Version)
+	return encodeRequest(ctx, req, r.People)
 }
 
 func encodeDeletePeopleRequest(ctx context.Context, req *http.Request, request interface{}) error {
@@ -197,13 +472,89 @@ func encodeDeletePeopleRequest(ctx context.Context, req *http.Request, request i
 	r := request.(transport.DeletePeopleRequest)
 	peopleID := url.QueryEscape(r.ID.String())
 	req.URL.Path = "/people/" + peopleID
-	return encodeRequest(ctx, req, request)
+	setIfMatch(req, r.Version)
+	return nil
+}
+
+func encodeRestorePeopleRequest(ctx context.Context, req *http.Request, request interface{}) error {
+	// r.Methods("POST").Path("/people/{uuid}/restore")
+	r := request.(transport.RestorePeopleRequest)
+	peopleID := url.QueryEscape(r.ID.String())
+	req.URL.Path = "/people/" + peopleID + "/restore"
+	return nil
+}
+
+func encodeGetPeopleHistoryRequest(ctx context.Context, req *http.Request, request interface{}) error {
+	// r.Methods("GET").Path("/people/{uuid}/history")
+	r := request.(transport.GetPeopleHistoryRequest)
+	peopleID := url.QueryEscape(r.ID.String())
+	req.URL.Path = "/people/" + peopleID + "/history"
+	return nil
 }
 
 func encodeGetPeopleRequest(ctx context.Context, req *http.Request, request interface{}) error {
 	// r.Methods("GET").Path("/people/")
+	r := request.(transport.GetPeopleRequest)
+	q := url.Values{}
+	if r.Limit != 0 {
+		q.Set("limit", strconv.Itoa(r.Limit))
+	}
+	if r.Offset != 0 {
+		q.Set("offset", strconv.Itoa(r.Offset))
+	}
+	if r.Cursor != "" {
+		q.Set("cursor", r.Cursor)
+	}
+	if len(r.Sort) > 0 {
+		q.Set("sort", titanic.FormatSort(r.Sort))
+	}
+	if r.IncludeDeleted {
+		q.Set("include_deleted", "true")
+	}
+	encodePeopleFilter(q, r.Filter)
 	req.URL.Path = "/people/"
-	return encodeRequest(ctx, req, request)
+	req.URL.RawQuery = q.Encode()
+	return nil
+}
+
+func encodePeopleFilter(q url.Values, f titanic.PeopleFilter) {
+	if f.Survived != nil {
+		q.Set("survived", strconv.FormatBool(*f.Survived))
+	}
+	if f.Pclass != nil {
+		q.Set("pclass", strconv.Itoa(*f.Pclass))
+	}
+	if f.Sex != "" {
+		q.Set("sex", f.Sex)
+	}
+	if f.AgeMin != nil {
+		q.Set("age_min", strconv.Itoa(*f.AgeMin))
+	}
+	if f.AgeMax != nil {
+		q.Set("age_max", strconv.Itoa(*f.AgeMax))
+	}
+	if f.FareMin != nil {
+		q.Set("fare_min", strconv.FormatFloat(float64(*f.FareMin), 'f', -1, 32))
+	}
+	if f.FareMax != nil {
+		q.Set("fare_max", strconv.FormatFloat(float64(*f.FareMax), 'f', -1, 32))
+	}
+}
+
+func encodeGetStatisticsRequest(ctx context.Context, req *http.Request, request interface{}) error {
+	// r.Methods("GET").Path("/people/stats")
+	r := request.(transport.GetStatisticsRequest)
+	q := url.Values{}
+	if len(r.GroupBy) > 0 {
+		q.Set("group_by", strings.Join(r.GroupBy, ","))
+	}
+	if r.AgeBandWidth != 0 {
+		q.Set("age_band", strconv.Itoa(r.AgeBandWidth))
+	}
+	encodePeopleFilter(q, r.Filter)
+	req.URL.Path = "/people/stats"
+	req.URL.RawQuery = q.Encode()
+	return nil
 }
 
 func encodeGetAPIStatusRequest(ctx context.Context, req *http.Request, request interface{}) error {
@@ -214,36 +565,90 @@ func encodeGetAPIStatusRequest(ctx context.Context, req *http.Request, request i
 
 func decodePostPeopleResponse(_ context.Context, resp *http.Response) (interface{}, error) {
 	var response transport.PostPeopleResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
+	err := json.NewDecoder(resp.Body).Decode(&response)
+	return response, err
+}
+
+func decodePostPeopleBatchResponse(_ context.Context, resp *http.Response) (interface{}, error) {
+	var response transport.PostPeopleBatchResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
 	err := json.NewDecoder(resp.Body).Decode(&response)
 	return response, err
 }
 
 func decodeGetPeopleByIDResponse(_ context.Context, resp *http.Response) (interface{}, error) {
-	var response transport.GetPeopleByIDRequest
+	var response transport.GetPeopleByIDResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
 	err := json.NewDecoder(resp.Body).Decode(&response)
 	return response, err
 }
 
 func decodePutPeopleResponse(_ context.Context, resp *http.Response) (interface{}, error) {
 	var response transport.PutPeopleResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
 	err := json.NewDecoder(resp.Body).Decode(&response)
 	return response, err
 }
 
 func decodePatchPeopleResponse(_ context.Context, resp *http.Response) (interface{}, error) {
 	var response transport.PatchPeopleResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
 	err := json.NewDecoder(resp.Body).Decode(&response)
 	return response, err
 }
 
 func decodeDeletePeopleResponse(_ context.Context, resp *http.Response) (interface{}, error) {
 	var response transport.DeletePeopleResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
+	err := json.NewDecoder(resp.Body).Decode(&response)
+	return response, err
+}
+
+func decodeRestorePeopleResponse(_ context.Context, resp *http.Response) (interface{}, error) {
+	var response transport.RestorePeopleResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
+	err := json.NewDecoder(resp.Body).Decode(&response)
+	return response, err
+}
+
+func decodeGetPeopleHistoryResponse(_ context.Context, resp *http.Response) (interface{}, error) {
+	var response transport.GetPeopleHistoryResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
 	err := json.NewDecoder(resp.Body).Decode(&response)
 	return response, err
 }
 
 func decodeGetPeopleResponse(_ context.Context, resp *http.Response) (interface{}, error) {
 	var response transport.GetPeopleResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
+	err := json.NewDecoder(resp.Body).Decode(&response)
+	return response, err
+}
+
+func decodeGetStatisticsResponse(_ context.Context, resp *http.Response) (interface{}, error) {
+	var response transport.GetStatisticsResponse
+	if response.Err = errorFrom(resp); response.Err != nil {
+		return response, nil
+	}
 	err := json.NewDecoder(resp.Body).Decode(&response)
 	return response, err
 }
@@ -259,7 +664,7 @@ func decodeGetAPIStatusResponse(_ context.Context, resp *http.Response) (interfa
 // trigger an endpoint (transport-level) error. For more information, read the
 // big comment in endpoints.go.
 type errorer interface {
-	error() error
+	Failed() error
 }
 
 // encodeResponse is the common method to encode all response types to the
@@ -267,30 +672,34 @@ type errorer interface {
 // reason to provide anything more specific. It's certainly possible to
 // specialize on a per-response (per-method) basis.
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
-	if e, ok := response.(errorer); ok && e.error() != nil {
+	if e, ok := response.(errorer); ok && e.Failed() != nil {
 		// Not a Go kit transport error, but a business-logic error.
 		// Provide those as HTTP errors.
-		encodeError(ctx, e.error(), w)
+		encodeError(ctx, e.Failed(), w)
 		return nil
 	}
 
-	// Configure the http security headers
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	setSecurityHeaders(w)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// setSecurityHeaders configures the http security headers.
+func setSecurityHeaders(w http.ResponseWriter) {
 	w.Header().Set("X-XSS-Protection", "1; mode=block")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Header().Set("X-Frame-Options", "SAMEORIGIN")
 	w.Header().Set("Content-Security-Policy", "upgrade-insecure-requests;")
-
-	return json.NewEncoder(w).Encode(response)
 }
 
 func encodeStatusResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	started := time.Now()
 
-	if e, ok := response.(errorer); ok && e.error() != nil {
+	if e, ok := response.(errorer); ok && e.Failed() != nil {
 		// Not a Go kit transport error, but a business-logic error.
 		// Provide those as HTTP errors.
-		encodeError(ctx, e.error(), w)
+		encodeError(ctx, e.Failed(), w)
 		return nil
 	}
 	duration := time.Since(started)
@@ -316,28 +725,7 @@ func encodeRequest(_ context.Context, req *http.Request, request interface{}) er
 	if err != nil {
 		return err
 	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Body = ioutil.NopCloser(&buf)
 	return nil
 }
-
-func encodeError(_ context.Context, err error, w http.ResponseWriter) {
-	if err == nil {
-		panic("encodeError with nil error")
-	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(codeFrom(err))
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
-}
-
-func codeFrom(err error) int {
-	switch err {
-	case titanic.ErrNotFound:
-		return http.StatusNotFound
-	case titanic.ErrAlreadyExists, titanic.ErrInconsistentIDs:
-		return http.StatusBadRequest
-	default:
-		return http.StatusInternalServerError
-	}
-}
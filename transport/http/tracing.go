@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/hyperd/titanic/tracing"
+)
+
+// traceRequests starts a span for each routed request, continuing the trace
+// of the incoming traceparent header, if any.
+func traceRequests(tracer *tracing.Tracer) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := "HTTP " + r.Method
+			if route := mux.CurrentRoute(r); route != nil {
+				if tpl, err := route.GetPathTemplate(); err == nil {
+					name += " " + tpl
+				}
+			}
+
+			ctx, span := tracer.Start(r.Context(), name, tracing.Extract(r.Header))
+			defer span.End()
+			span.SetAttributes("http.method", r.Method, "http.target", r.URL.RequestURI())
+
+			sw := &statusWriter{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+			span.SetAttributes("http.status_code", sw.code)
+		})
+	}
+}
+
+// statusWriter records the status code of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush implements http.Flusher, so that streamed responses are still
+// flushed to the client.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
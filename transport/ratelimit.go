@@ -0,0 +1,25 @@
+package transport
+
+import (
+	"gitlab.com/hyperd/titanic/ratelimit"
+)
+
+// RateLimit returns the endpoints rate limited by the limiter, each request
+// costing the tokens of its method. The API status, probed by the
+// orchestrator, isn't limited.
+func RateLimit(e Endpoints, l *ratelimit.Limiter) Endpoints {
+	e.PostPeopleEndpoint = l.Middleware("PostPeople")(e.PostPeopleEndpoint)
+	e.PostPeopleBatchEndpoint = l.Middleware("PostPeopleBatch")(e.PostPeopleBatchEndpoint)
+	e.ImportPeopleEndpoint = l.Middleware("ImportPeople")(e.ImportPeopleEndpoint)
+	e.GetPeopleByIDEndpoint = l.Middleware("GetPeopleByID")(e.GetPeopleByIDEndpoint)
+	e.PutPeopleEndpoint = l.Middleware("PutPeople")(e.PutPeopleEndpoint)
+	e.PatchPeopleEndpoint = l.Middleware("PatchPeople")(e.PatchPeopleEndpoint)
+	e.DeletePeopleEndpoint = l.Middleware("DeletePeople")(e.DeletePeopleEndpoint)
+	e.RestorePeopleEndpoint = l.Middleware("RestorePeople")(e.RestorePeopleEndpoint)
+	e.GetPeopleHistoryEndpoint = l.Middleware("GetPeopleHistory")(e.GetPeopleHistoryEndpoint)
+	e.GetPeopleEndpoint = l.Middleware("GetPeople")(e.GetPeopleEndpoint)
+	e.ExportPeopleEndpoint = l.Middleware("ExportPeople")(e.ExportPeopleEndpoint)
+	e.WatchPeopleEndpoint = l.Middleware("WatchPeople")(e.WatchPeopleEndpoint)
+	e.GetStatisticsEndpoint = l.Middleware("GetStatistics")(e.GetStatisticsEndpoint)
+	return e
+}
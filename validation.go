@@ -0,0 +1,128 @@
+package titanic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FieldError is a problem with the value of a single field, named after its
+// JSON key.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError lists the problems with the fields of one or more
+// passengers.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return "invalid people: " + strings.Join(msgs, "; ")
+}
+
+// Bounds of the fields of People.
+const (
+	MinNameLength = 2
+	MaxNameLength = 100
+	MaxAge        = 116
+	MaxRelatives  = 20
+)
+
+// nameSymbols are the characters allowed in the names besides the letters
+// and the spaces, e.g. in "Mrs. John Bradley (Florence Briggs Thayer) Cumings".
+const nameSymbols = `.,'"()-/`
+
+// Validate checks the fields of the passenger, returning a *ValidationError
+// listing the problems found, if any. A partial passenger, i.e. a patch,
+// only has the fields it sets checked, while the name of a whole passenger
+// is required.
+func (p People) Validate(partial bool) error {
+	if fields := p.validate("", partial); len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// ValidatePeople checks the fields of each passenger as Validate does, the
+// fields being prefixed by the index of the passenger, e.g. "[3].name".
+func ValidatePeople(people []People) error {
+	var fields []FieldError
+	for i, p := range people {
+		fields = append(fields, p.validate(fmt.Sprintf("[%d].", i), false)...)
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func (p People) validate(prefix string, partial bool) []FieldError {
+	var fields []FieldError
+	fail := func(field, msg string) {
+		fields = append(fields, FieldError{Field: prefix + field, Message: msg})
+	}
+
+	if p.Pclass != nil && (*p.Pclass < 1 || *p.Pclass > 3) {
+		fail("pclass", "must be 1, 2 or 3")
+	}
+	switch {
+	case p.Name == "" && !partial:
+		fail("name", "must not be empty")
+	case p.Name != "":
+		if msg := validateName(p.Name); msg != "" {
+			fail("name", msg)
+		}
+	}
+	switch p.Sex {
+	case "", "male", "female", "not declared":
+	default:
+		fail("sex", "must be male, female or not declared")
+	}
+	if p.Age != nil && (*p.Age < 0 || *p.Age > MaxAge) {
+		fail("age", fmt.Sprintf("must be an integer between 0 and %d", MaxAge))
+	}
+	if p.SiblingsSpousesAbroad != nil && (*p.SiblingsSpousesAbroad < 0 || *p.SiblingsSpousesAbroad > MaxRelatives) {
+		fail("siblings_spouses_abroad", fmt.Sprintf("must be an integer between 0 and %d", MaxRelatives))
+	}
+	if p.ParentsChildrenAboard != nil && (*p.ParentsChildrenAboard < 0 || *p.ParentsChildrenAboard > MaxRelatives) {
+		fail("parents_children_aboard", fmt.Sprintf("must be an integer between 0 and %d", MaxRelatives))
+	}
+	if p.Fare != nil {
+		if f := float64(*p.Fare); f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+			fail("fare", "must be a non-negative number")
+		}
+	}
+	if p.Version < 0 && p.Version != AnyVersion {
+		fail("version", "must not be negative")
+	}
+	return fields
+}
+
+// validateName returns the problem with the name, or "".
+func validateName(name string) string {
+	if n := utf8.RuneCountInString(name); n < MinNameLength || n > MaxNameLength {
+		return fmt.Sprintf("must be between %d and %d characters long", MinNameLength, MaxNameLength)
+	}
+	if strings.TrimSpace(name) != name {
+		return "must not start or end with a space"
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) && r != ' ' && !strings.ContainsRune(nameSymbols, r) {
+			return fmt.Sprintf("must only contain letters, spaces and %s", nameSymbols)
+		}
+	}
+	return ""
+}